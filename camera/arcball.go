@@ -0,0 +1,143 @@
+package camera
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// ArcballMapping is the way an Arcball maps the viewport to a sphere.
+type ArcballMapping int
+
+// The mappings of an Arcball.
+const (
+	// ArcballSphere is the mapping of Shoemake's arcball: the points in the
+	// circle are on the front of the unit sphere, the points outside of it on
+	// its silhouette.
+	ArcballSphere ArcballMapping = iota
+
+	// ArcballHyperbolic is the mapping of Bell's trackball: the unit sphere
+	// near the center of the circle, then a hyperbolic sheet, so dragging
+	// outside of the circle still rotates around the axes of the view plane.
+	ArcballHyperbolic
+)
+
+// Arcball rotates an object by dragging a point of a virtual sphere around
+// it, the usual way of rotating models with the mouse. The viewport positions
+// are window coordinates, y up like glm.Project, and the rotations are in view
+// space.
+type Arcball struct {
+	// Center and Radius are the circle of the sphere on the viewport, in
+	// pixels.
+	Center glm.Vec2
+	Radius float32
+
+	// Mapping is the way the viewport is mapped to the sphere.
+	Mapping ArcballMapping
+
+	// Axis constrains the rotations around it if it isn't zero.
+	Axis glm.Vec3
+
+	// Orientation is the rotation of the object.
+	Orientation glm.Quat
+
+	// the point of the sphere and the orientation at the start of the drag.
+	start            glm.Vec3
+	startOrientation glm.Quat
+	dragging         bool
+}
+
+// NewArcball returns an arcball with the given circle on the viewport and no
+// rotation.
+func NewArcball(center *glm.Vec2, radius float32, mapping ArcballMapping) *Arcball {
+	return &Arcball{
+		Center:      *center,
+		Radius:      radius,
+		Mapping:     mapping,
+		Orientation: glm.QuatIdent(),
+	}
+}
+
+// Begin starts dragging at the viewport position p.
+func (a *Arcball) Begin(p *glm.Vec2) {
+	a.start = a.Point(p)
+	a.startOrientation = a.Orientation
+	a.dragging = true
+}
+
+// Drag sets the orientation to the orientation at the start of the drag
+// followed by the rotation from the start of the drag to the viewport
+// position p. It does nothing if there is no drag in progress.
+func (a *Arcball) Drag(p *glm.Vec2) {
+	if !a.dragging {
+		return
+	}
+	to := a.Point(p)
+	r := a.between(&a.start, &to)
+	a.Orientation = r.Mul(&a.startOrientation)
+	a.Orientation.Normalize()
+}
+
+// End stops dragging.
+func (a *Arcball) End() {
+	a.dragging = false
+}
+
+// Rotation returns the rotation dragging from the viewport position from to
+// the viewport position to.
+func (a *Arcball) Rotation(from, to *glm.Vec2) glm.Quat {
+	start, end := a.Point(from), a.Point(to)
+	return a.between(&start, &end)
+}
+
+// Point returns the unit vector from the center of the sphere to the point of
+// the sphere under the viewport position p. If Axis isn't zero the point is
+// projected on the great circle perpendicular to it.
+func (a *Arcball) Point(p *glm.Vec2) glm.Vec3 {
+	v := glm.Vec3{(p[0] - a.Center[0]) / a.Radius, (p[1] - a.Center[1]) / a.Radius, 0}
+	d2 := v[0]*v[0] + v[1]*v[1]
+	switch a.Mapping {
+	case ArcballHyperbolic:
+		if d2 <= 0.5 {
+			v[2] = math.Sqrt(1 - d2)
+		} else {
+			v[2] = 0.5 / math.Sqrt(d2)
+		}
+	default:
+		if d2 <= 1 {
+			v[2] = math.Sqrt(1 - d2)
+		}
+	}
+	v.Normalize()
+
+	if a.Axis == (glm.Vec3{}) {
+		return v
+	}
+	axis := a.Axis.Normalized()
+	v.AddScaledVec(-v.Dot(&axis), &axis)
+	if v.Len2() == 0 {
+		// p is on the axis, take the point of the great circle facing the
+		// viewer, or any point if the axis is the view direction.
+		v = glm.Vec3{0, 0, 1}
+		if axis[0] == 0 && axis[1] == 0 {
+			v = glm.Vec3{1, 0, 0}
+		}
+		v.AddScaledVec(-v.Dot(&axis), &axis)
+	}
+	v.Normalize()
+	return v
+}
+
+// between returns the rotation from the point from of the sphere to the point
+// to.
+func (a *Arcball) between(from, to *glm.Vec3) glm.Quat {
+	if a.Axis == (glm.Vec3{}) {
+		return glm.QuatBetweenVectors(from, to)
+	}
+
+	// QuatBetweenVectors picks any axis for opposite vectors, the angle
+	// around Axis is computed directly instead.
+	axis := a.Axis.Normalized()
+	cross := from.Cross(to)
+	angle := math.Atan2(cross.Dot(&axis), from.Dot(to))
+	return glm.QuatRotate(angle, &axis)
+}
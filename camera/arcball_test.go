@@ -0,0 +1,109 @@
+package camera
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestArcball_Point(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		mapping  ArcballMapping
+		axis     glm.Vec3
+		p        glm.Vec2
+		expected glm.Vec3
+	}{
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{100, 50}, glm.Vec3{0, 0, 1}},
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{110, 50}, glm.Vec3{1, 0, 0}},
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{100, 44}, glm.Vec3{0, -0.6, 0.8}},
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{100, 80}, glm.Vec3{0, 1, 0}},
+		{ArcballHyperbolic, glm.Vec3{}, glm.Vec2{100, 50}, glm.Vec3{0, 0, 1}},
+		{ArcballHyperbolic, glm.Vec3{}, glm.Vec2{106, 50}, glm.Vec3{0.6, 0, 0.8}},
+		// Outside of the sphere the point is (2, 0, 0.25).
+		{ArcballHyperbolic, glm.Vec3{}, glm.Vec2{120, 50}, glm.Vec3{2 / math.Sqrt(4.0625), 0, 0.25 / math.Sqrt(4.0625)}},
+		{ArcballSphere, glm.Vec3{0, 2, 0}, glm.Vec2{106, 58}, glm.Vec3{0.6, 0, 0}},
+		{ArcballSphere, glm.Vec3{0, 1, 0}, glm.Vec2{100, 60}, glm.Vec3{0, 0, 1}},
+	}
+
+	for i, test := range tests {
+		a := NewArcball(&glm.Vec2{100, 50}, 10, test.mapping)
+		a.Axis = test.axis
+		p := a.Point(&test.p)
+		if test.axis != (glm.Vec3{}) {
+			// Projected on the great circle, the point is normalized
+			// afterward.
+			test.expected.Normalize()
+		}
+		if !near(p[:], test.expected[:]) {
+			t.Errorf("[%d] point = %s, want %s", i, p.String(), test.expected.String())
+		}
+		if l := p.Len(); !glm.FloatEqualThreshold(l, 1, 1e-4) {
+			t.Errorf("[%d] point length = %f, want 1", i, l)
+		}
+	}
+}
+
+func TestArcball_Rotation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		mapping  ArcballMapping
+		axis     glm.Vec3
+		from, to glm.Vec2
+		expected glm.Quat
+	}{
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{0, 0}, glm.Vec2{0, 0}, glm.QuatIdent()},
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{0, 0}, glm.Vec2{10, 0}, glm.QuatRotate(math.Pi/2, &glm.Vec3{0, 1, 0})},
+		{ArcballSphere, glm.Vec3{}, glm.Vec2{0, 0}, glm.Vec2{0, 30}, glm.QuatRotate(-math.Pi/2, &glm.Vec3{1, 0, 0})},
+		{ArcballHyperbolic, glm.Vec3{}, glm.Vec2{0, 0}, glm.Vec2{-6, 0}, glm.QuatRotate(-math.Asin(0.6), &glm.Vec3{0, 1, 0})},
+		{ArcballSphere, glm.Vec3{0, 0, 1}, glm.Vec2{10, 0}, glm.Vec2{0, 10}, glm.QuatRotate(math.Pi/2, &glm.Vec3{0, 0, 1})},
+		// Opposite points still rotate around the axis.
+		{ArcballSphere, glm.Vec3{0, 1, 0}, glm.Vec2{-20, 0}, glm.Vec2{20, 0}, glm.QuatRotate(math.Pi, &glm.Vec3{0, 1, 0})},
+		{ArcballSphere, glm.Vec3{0, 1, 0}, glm.Vec2{0, 0}, glm.Vec2{6, 8}, glm.QuatRotate(math.Atan2(0.6, 0), &glm.Vec3{0, 1, 0})},
+	}
+
+	for i, test := range tests {
+		a := NewArcball(&glm.Vec2{0, 0}, 10, test.mapping)
+		a.Axis = test.axis
+		q := a.Rotation(&test.from, &test.to)
+		if !q.OrientationEqualThreshold(&test.expected, 1e-4) {
+			t.Errorf("[%d] rotation = %v, want %v", i, q, test.expected)
+		}
+
+		// The rotation moves the start point to the end point.
+		from, to := a.Point(&test.from), a.Point(&test.to)
+		if v := q.Rotate(&from); !near(v[:], to[:]) {
+			t.Errorf("[%d] rotated start point = %s, want %s", i, v.String(), to.String())
+		}
+	}
+}
+
+func TestArcball_Drag(t *testing.T) {
+	t.Parallel()
+	a := NewArcball(&glm.Vec2{0, 0}, 10, ArcballSphere)
+
+	// Drag without Begin does nothing.
+	a.Drag(&glm.Vec2{10, 0})
+	if iden := glm.QuatIdent(); a.Orientation != iden {
+		t.Errorf("orientation = %v before any drag, want identity", a.Orientation)
+	}
+
+	// 2 drags of 90 degrees around Y, the second one going through other
+	// positions first.
+	for _, positions := range [][]glm.Vec2{
+		{{0, 0}, {10, 0}},
+		{{0, 0}, {5, 5}, {-3, 2}, {10, 0}},
+	} {
+		a.Begin(&positions[0])
+		for n := range positions[1:] {
+			a.Drag(&positions[n+1])
+		}
+		a.End()
+	}
+	a.Drag(&glm.Vec2{0, 10})
+
+	expected := glm.QuatRotate(math.Pi, &glm.Vec3{0, 1, 0})
+	if !a.Orientation.OrientationEqualThreshold(&expected, 1e-4) {
+		t.Errorf("orientation = %v, want %v", a.Orientation, expected)
+	}
+}
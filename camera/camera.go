@@ -0,0 +1,240 @@
+// Package camera implements the usual camera controllers: orbiting around a
+// target, first-person and free-fly. The controllers are driven by deltas,
+// typically from the mouse and keyboard, and produce view matrices. The world
+// is Y up and cameras look toward -Z when they aren't rotated, like
+// glm.LookAtV.
+package camera
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// defaultMaxPitch is the default limit of the pitch of the cameras, just under
+// 90 degrees so the view never flips over the vertical.
+const defaultMaxPitch = math.Pi/2 - 0.001
+
+// worldUp is the up direction of the world.
+var worldUp = glm.Vec3{0, 1, 0}
+
+// Orbit is a camera that rotates around a target, always looking at it.
+type Orbit struct {
+	// Target is the point the camera looks at.
+	Target glm.Vec3
+
+	// Distance is the distance from the camera to Target, clamped to
+	// [MinDistance, MaxDistance].
+	Distance, MinDistance, MaxDistance float32
+
+	// Yaw is the angle of the camera around the vertical axis of Target. At 0
+	// the camera is on the +Z side of Target. It is kept in [-pi, pi].
+	Yaw float32
+
+	// Pitch is the angle of the camera above the horizontal plane of Target,
+	// clamped to [-MaxPitch, MaxPitch].
+	Pitch, MaxPitch float32
+}
+
+// NewOrbit returns an orbit camera looking at target from distance, which can
+// vary between minDistance and maxDistance.
+func NewOrbit(target *glm.Vec3, distance, minDistance, maxDistance float32) *Orbit {
+	return &Orbit{
+		Target:      *target,
+		Distance:    glm.Clamp(distance, minDistance, maxDistance),
+		MinDistance: minDistance,
+		MaxDistance: maxDistance,
+		MaxPitch:    defaultMaxPitch,
+	}
+}
+
+// Rotate moves the camera around the target by the angles dyaw, to the right
+// when positive, and dpitch, upward when positive.
+func (o *Orbit) Rotate(dyaw, dpitch float32) {
+	o.Yaw = wrapAngle(o.Yaw + dyaw)
+	o.Pitch = glm.Clamp(o.Pitch+dpitch, -o.MaxPitch, o.MaxPitch)
+}
+
+// Zoom multiplies the distance to the target by factor, zooming in when
+// factor is smaller than 1.
+func (o *Orbit) Zoom(factor float32) {
+	o.Distance = glm.Clamp(o.Distance*factor, o.MinDistance, o.MaxDistance)
+}
+
+// Pan moves the target, and the camera with it, by dx to the right of the view
+// and dy to its top.
+func (o *Orbit) Pan(dx, dy float32) {
+	eye := o.Eye()
+	forward := o.Target.Sub(&eye)
+	right := forward.Cross(&worldUp)
+	right.Normalize()
+	top := right.Cross(&forward)
+	top.Normalize()
+	o.Target.AddScaledVec(dx, &right)
+	o.Target.AddScaledVec(dy, &top)
+}
+
+// Eye returns the position of the camera.
+func (o *Orbit) Eye() glm.Vec3 {
+	sy, cy := math.Sincos(o.Yaw)
+	sp, cp := math.Sincos(o.Pitch)
+	eye := o.Target
+	eye.AddScaledVec(o.Distance, &glm.Vec3{cp * sy, sp, cp * cy})
+	return eye
+}
+
+// View returns the view matrix of the camera.
+func (o *Orbit) View() glm.Mat4 {
+	eye := o.Eye()
+	return glm.LookAtV(&eye, &o.Target, &worldUp)
+}
+
+// FPS is a first-person camera. It turns around the vertical axis and looks
+// up and down but never rolls.
+type FPS struct {
+	// Position is the position of the camera.
+	Position glm.Vec3
+
+	// Yaw is the angle of the camera around the vertical axis, to the left
+	// when positive. It is kept in [-pi, pi].
+	Yaw float32
+
+	// Pitch is the angle of the camera above the horizontal plane, clamped to
+	// [-MaxPitch, MaxPitch].
+	Pitch, MaxPitch float32
+}
+
+// NewFPS returns a first-person camera at position looking toward -Z.
+func NewFPS(position *glm.Vec3) *FPS {
+	return &FPS{Position: *position, MaxPitch: defaultMaxPitch}
+}
+
+// Look turns the camera by the angles dyaw, to the left when positive, and
+// dpitch, upward when positive.
+func (c *FPS) Look(dyaw, dpitch float32) {
+	c.Yaw = wrapAngle(c.Yaw + dyaw)
+	c.Pitch = glm.Clamp(c.Pitch+dpitch, -c.MaxPitch, c.MaxPitch)
+}
+
+// Move moves the camera by forward in the direction it faces projected on the
+// horizontal plane, by right to its right and by vertical upward, like
+// walking.
+func (c *FPS) Move(forward, right, vertical float32) {
+	s, co := math.Sincos(c.Yaw)
+	c.Position.AddScaledVec(forward, &glm.Vec3{-s, 0, -co})
+	c.Position.AddScaledVec(right, &glm.Vec3{co, 0, -s})
+	c.Position[1] += vertical
+}
+
+// Forward returns the unit direction the camera looks toward.
+func (c *FPS) Forward() glm.Vec3 {
+	sy, cy := math.Sincos(c.Yaw)
+	sp, cp := math.Sincos(c.Pitch)
+	return glm.Vec3{-sy * cp, sp, -cy * cp}
+}
+
+// Orientation returns the rotation of the camera, the yaw around the world
+// vertical axis applied after the pitch around the camera right axis.
+func (c *FPS) Orientation() glm.Quat {
+	yaw := glm.QuatRotate(c.Yaw, &worldUp)
+	pitch := glm.QuatRotate(c.Pitch, &glm.Vec3{1, 0, 0})
+	return yaw.Mul(&pitch)
+}
+
+// Transform returns the transform from the camera space to the world.
+func (c *FPS) Transform() glm.Transform {
+	return cameraTransform(&c.Position, c.Orientation())
+}
+
+// View returns the view matrix of the camera.
+func (c *FPS) View() glm.Mat4 {
+	return viewMatrix(&c.Position, c.Orientation())
+}
+
+// FreeFly is a camera with 6 degrees of freedom. It rotates and moves along
+// its own axes, with no notion of the vertical.
+type FreeFly struct {
+	// Position is the position of the camera.
+	Position glm.Vec3
+
+	// Orientation is the rotation from the camera space to the world.
+	Orientation glm.Quat
+}
+
+// NewFreeFly returns a free-fly camera at position with the given
+// orientation.
+func NewFreeFly(position *glm.Vec3, orientation *glm.Quat) *FreeFly {
+	return &FreeFly{Position: *position, Orientation: orientation.Normalized()}
+}
+
+// Rotate turns the camera around its own axes: by dyaw to the left, dpitch
+// upward and droll counter-clockwise, when positive.
+func (c *FreeFly) Rotate(dyaw, dpitch, droll float32) {
+	yaw := glm.QuatRotate(dyaw, &glm.Vec3{0, 1, 0})
+	pitch := glm.QuatRotate(dpitch, &glm.Vec3{1, 0, 0})
+	roll := glm.QuatRotate(droll, &glm.Vec3{0, 0, 1})
+	c.Orientation.MulWith(&yaw)
+	c.Orientation.MulWith(&pitch)
+	c.Orientation.MulWith(&roll)
+	c.Orientation.Normalize()
+}
+
+// LookAt turns the camera toward center, its top toward up.
+func (c *FreeFly) LookAt(center, up *glm.Vec3) {
+	// QuatLookAtV needs an up perpendicular to the direction.
+	direction := center.Sub(&c.Position)
+	right := direction.Cross(up)
+	top := right.Cross(&direction)
+	view := glm.QuatLookAtV(&c.Position, center, &top)
+	c.Orientation = view.Conjugated()
+	c.Orientation.Normalize()
+}
+
+// Move moves the camera by forward, right and up along its own axes.
+func (c *FreeFly) Move(forward, right, up float32) {
+	local := glm.Vec3{right, up, -forward}
+	d := c.Orientation.Rotate(&local)
+	c.Position.AddWith(&d)
+}
+
+// Forward returns the unit direction the camera looks toward.
+func (c *FreeFly) Forward() glm.Vec3 {
+	return c.Orientation.Rotate(&glm.Vec3{0, 0, -1})
+}
+
+// Transform returns the transform from the camera space to the world.
+func (c *FreeFly) Transform() glm.Transform {
+	return cameraTransform(&c.Position, c.Orientation)
+}
+
+// View returns the view matrix of the camera.
+func (c *FreeFly) View() glm.Mat4 {
+	return viewMatrix(&c.Position, c.Orientation)
+}
+
+// cameraTransform returns the transform from the camera space to the world of
+// a camera at position with the rotation orientation.
+func cameraTransform(position *glm.Vec3, orientation glm.Quat) glm.Transform {
+	t := glm.NewTransform()
+	t.SetTranslateVec3(position)
+	t.RotateQuat(&orientation)
+	return t
+}
+
+// viewMatrix returns the transform from the world to the camera space of a
+// camera at position with the rotation orientation.
+func viewMatrix(position *glm.Vec3, orientation glm.Quat) glm.Mat4 {
+	t := glm.NewTransform()
+	inverse := orientation.Conjugated()
+	t.SetRotateQuat(&inverse)
+	t.TranslateVec3(&glm.Vec3{-position[0], -position[1], -position[2]})
+	return t.Mat4()
+}
+
+// wrapAngle returns the angle a in [-pi, pi].
+func wrapAngle(a float32) float32 {
+	a = math.Mod(a+math.Pi, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a - math.Pi
+}
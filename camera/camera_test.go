@@ -0,0 +1,303 @@
+package camera
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+// near returns true if a and b are within 1e-4 of each other, unlike
+// EqualThreshold which is relative.
+func near(a, b []float32) bool {
+	for n := range a {
+		if math.Abs(a[n]-b[n]) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+// transformPoint returns m*p.
+func transformPoint(m *glm.Mat4, p *glm.Vec3) glm.Vec3 {
+	v := m.Mul4x1(&glm.Vec4{p[0], p[1], p[2], 1})
+	return glm.Vec3{v[0], v[1], v[2]}
+}
+
+func TestOrbit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		target         glm.Vec3
+		distance       float32
+		dyaw, dpitch   float32
+		zoom           float32
+		expectedEye    glm.Vec3
+		expectedDist   float32
+		expectedPitch  float32
+		expectedTarget glm.Vec3
+	}{
+		{
+			target:         glm.Vec3{0, 0, 0},
+			distance:       5,
+			zoom:           1,
+			expectedEye:    glm.Vec3{0, 0, 5},
+			expectedDist:   5,
+			expectedTarget: glm.Vec3{0, 0, 0},
+		},
+		{
+			target:         glm.Vec3{1, 2, 3},
+			distance:       5,
+			dyaw:           math.Pi / 2,
+			zoom:           0.5,
+			expectedEye:    glm.Vec3{3.5, 2, 3},
+			expectedDist:   2.5,
+			expectedTarget: glm.Vec3{1, 2, 3},
+		},
+		{
+			// Zoom and pitch are clamped.
+			target:         glm.Vec3{0, 0, 0},
+			distance:       5,
+			dpitch:         3,
+			zoom:           100,
+			expectedEye:    glm.Vec3{0, 10, 0.01},
+			expectedDist:   10,
+			expectedPitch:  defaultMaxPitch,
+			expectedTarget: glm.Vec3{0, 0, 0},
+		},
+		{
+			target:         glm.Vec3{0, 0, 0},
+			distance:       5,
+			dpitch:         -math.Pi / 4,
+			zoom:           0.01,
+			expectedEye:    glm.Vec3{0, -math.Sqrt2 / 2, math.Sqrt2 / 2},
+			expectedDist:   1,
+			expectedPitch:  -math.Pi / 4,
+			expectedTarget: glm.Vec3{0, 0, 0},
+		},
+	}
+
+	for i, test := range tests {
+		o := NewOrbit(&test.target, test.distance, 1, 10)
+		o.Rotate(test.dyaw, test.dpitch)
+		o.Zoom(test.zoom)
+
+		if o.Distance != test.expectedDist {
+			t.Errorf("[%d] distance = %f, want %f", i, o.Distance, test.expectedDist)
+		}
+		if !glm.FloatEqualThreshold(o.Pitch, test.expectedPitch, 1e-4) {
+			t.Errorf("[%d] pitch = %f, want %f", i, o.Pitch, test.expectedPitch)
+		}
+		eye := o.Eye()
+		if !near(eye[:], test.expectedEye[:]) {
+			t.Errorf("[%d] eye = %s, want %s", i, eye.String(), test.expectedEye.String())
+		}
+
+		// The target is in front of the camera.
+		view := o.View()
+		target := transformPoint(&view, &o.Target)
+		if expected := (glm.Vec3{0, 0, -o.Distance}); !near(target[:], expected[:]) {
+			t.Errorf("[%d] target in view space = %s, want %s", i, target.String(), expected.String())
+		}
+	}
+}
+
+func TestOrbit_Pan(t *testing.T) {
+	t.Parallel()
+	o := NewOrbit(&glm.Vec3{0, 0, 0}, 5, 1, 10)
+	o.Rotate(math.Pi/2, 0)
+	o.Pan(1, 2)
+
+	// Looking toward -X, the right of the view is -Z.
+	if expected := (glm.Vec3{0, 2, -1}); !near(o.Target[:], expected[:]) {
+		t.Errorf("target = %s, want %s", o.Target.String(), expected.String())
+	}
+	view := o.View()
+	target := transformPoint(&view, &o.Target)
+	if expected := (glm.Vec3{0, 0, -5}); !near(target[:], expected[:]) {
+		t.Errorf("target in view space = %s, want %s", target.String(), expected.String())
+	}
+}
+
+func TestFPS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		dyaw, dpitch               float32
+		forward, right, up         float32
+		expectedForward            glm.Vec3
+		expectedPosition           glm.Vec3
+		expectedYaw, expectedPitch float32
+	}{
+		{
+			forward:          1,
+			expectedForward:  glm.Vec3{0, 0, -1},
+			expectedPosition: glm.Vec3{1, 2, 2},
+		},
+		{
+			dyaw:             math.Pi / 2,
+			forward:          2,
+			right:            1,
+			expectedForward:  glm.Vec3{-1, 0, 0},
+			expectedPosition: glm.Vec3{-1, 2, 2},
+			expectedYaw:      math.Pi / 2,
+		},
+		{
+			// Looking up doesn't move the camera upward.
+			dpitch:           math.Pi / 4,
+			forward:          1,
+			up:               3,
+			expectedForward:  glm.Vec3{0, math.Sqrt2 / 2, -math.Sqrt2 / 2},
+			expectedPosition: glm.Vec3{1, 5, 2},
+			expectedPitch:    math.Pi / 4,
+		},
+		{
+			dyaw:             3 * math.Pi / 2,
+			dpitch:           -4,
+			expectedForward:  glm.Vec3{0.001, -1, 0},
+			expectedPosition: glm.Vec3{1, 2, 3},
+			expectedYaw:      -math.Pi / 2,
+			expectedPitch:    -defaultMaxPitch,
+		},
+	}
+
+	for i, test := range tests {
+		c := NewFPS(&glm.Vec3{1, 2, 3})
+		c.Look(test.dyaw, test.dpitch)
+		c.Move(test.forward, test.right, test.up)
+
+		if !glm.FloatEqualThreshold(c.Yaw, test.expectedYaw, 1e-4) || !glm.FloatEqualThreshold(c.Pitch, test.expectedPitch, 1e-4) {
+			t.Errorf("[%d] yaw, pitch = %f, %f, want %f, %f", i, c.Yaw, c.Pitch, test.expectedYaw, test.expectedPitch)
+		}
+		forward := c.Forward()
+		if !near(forward[:], test.expectedForward[:]) {
+			t.Errorf("[%d] forward = %s, want %s", i, forward.String(), test.expectedForward.String())
+		}
+		if !near(c.Position[:], test.expectedPosition[:]) {
+			t.Errorf("[%d] position = %s, want %s", i, c.Position.String(), test.expectedPosition.String())
+		}
+
+		// The orientation turns -Z to the forward direction.
+		q := c.Orientation()
+		if v := q.Rotate(&glm.Vec3{0, 0, -1}); !near(v[:], forward[:]) {
+			t.Errorf("[%d] orientation turns -Z to %s, want %s", i, v.String(), forward.String())
+		}
+		checkView(t, i, &c.Position, &forward, c.View(), c.Transform())
+	}
+}
+
+func TestFreeFly(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		dyaw, dpitch, droll float32
+		forward, right, up  float32
+		expectedForward     glm.Vec3
+		expectedUp          glm.Vec3
+		expectedPosition    glm.Vec3
+	}{
+		{
+			forward:          1,
+			expectedForward:  glm.Vec3{0, 0, -1},
+			expectedUp:       glm.Vec3{0, 1, 0},
+			expectedPosition: glm.Vec3{0, 0, -1},
+		},
+		{
+			dyaw:             math.Pi / 2,
+			forward:          1,
+			right:            2,
+			expectedForward:  glm.Vec3{-1, 0, 0},
+			expectedUp:       glm.Vec3{0, 1, 0},
+			expectedPosition: glm.Vec3{-1, 0, -2},
+		},
+		{
+			// Pitch then yaw around the new vertical axis of the camera.
+			dyaw:             math.Pi / 2,
+			dpitch:           math.Pi / 2,
+			up:               1,
+			expectedForward:  glm.Vec3{-1, 0, 0},
+			expectedUp:       glm.Vec3{0, 0, 1},
+			expectedPosition: glm.Vec3{0, 0, 1},
+		},
+		{
+			droll:            math.Pi / 2,
+			right:            1,
+			expectedForward:  glm.Vec3{0, 0, -1},
+			expectedUp:       glm.Vec3{-1, 0, 0},
+			expectedPosition: glm.Vec3{0, 1, 0},
+		},
+	}
+
+	for i, test := range tests {
+		c := NewFreeFly(&glm.Vec3{0, 0, 0}, &glm.Quat{W: 1})
+		c.Rotate(0, test.dpitch, 0)
+		c.Rotate(test.dyaw, 0, test.droll)
+		c.Move(test.forward, test.right, test.up)
+
+		forward := c.Forward()
+		if !near(forward[:], test.expectedForward[:]) {
+			t.Errorf("[%d] forward = %s, want %s", i, forward.String(), test.expectedForward.String())
+		}
+		if up := c.Orientation.Rotate(&glm.Vec3{0, 1, 0}); !near(up[:], test.expectedUp[:]) {
+			t.Errorf("[%d] up = %s, want %s", i, up.String(), test.expectedUp.String())
+		}
+		if !near(c.Position[:], test.expectedPosition[:]) {
+			t.Errorf("[%d] position = %s, want %s", i, c.Position.String(), test.expectedPosition.String())
+		}
+		checkView(t, i, &c.Position, &forward, c.View(), c.Transform())
+	}
+}
+
+func TestFreeFly_LookAt(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		position, center, up glm.Vec3
+	}{
+		{glm.Vec3{0, 0, 0}, glm.Vec3{0, 0, -1}, glm.Vec3{0, 1, 0}},
+		{glm.Vec3{1, 2, 3}, glm.Vec3{4, 0, -2}, glm.Vec3{0, 1, 0}},
+		{glm.Vec3{1, 2, 3}, glm.Vec3{-3, 5, 3}, glm.Vec3{0, 0, 1}},
+		{glm.Vec3{-5, 0, 2}, glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 0}},
+	}
+
+	for i, test := range tests {
+		c := NewFreeFly(&test.position, &glm.Quat{W: 1})
+		c.LookAt(&test.center, &test.up)
+		expected := glm.LookAtV(&test.position, &test.center, &test.up)
+		if view := c.View(); !near(view[:], expected[:]) {
+			t.Errorf("[%d] view =\n%swant\n%s", i, view.String(), expected.String())
+		}
+	}
+}
+
+// checkView checks that the view matrix matches glm.LookAtV and that the
+// transform is its inverse.
+func checkView(t *testing.T, i int, position, forward *glm.Vec3, view glm.Mat4, transform glm.Transform) {
+	m := transform.Mat4()
+	center := position.Add(forward)
+	top := glm.Vec3{m[4], m[5], m[6]}
+	expected := glm.LookAtV(position, &center, &top)
+	if !near(view[:], expected[:]) {
+		t.Errorf("[%d] view =\n%swant\n%s", i, view.String(), expected.String())
+	}
+
+	product := view.Mul4(&m)
+	if iden := glm.Ident4(); !near(product[:], iden[:]) {
+		t.Errorf("[%d] view*transform =\n%swant identity", i, product.String())
+	}
+}
+
+func TestWrapAngle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		angle, expected float32
+	}{
+		{0, 0},
+		{1, 1},
+		{-1, -1},
+		{3 * math.Pi / 2, -math.Pi / 2},
+		{-3 * math.Pi / 2, math.Pi / 2},
+		{5 * math.Pi / 2, math.Pi / 2},
+	}
+
+	for i, test := range tests {
+		if a := wrapAngle(test.angle); !glm.FloatEqualThreshold(a, test.expected, 1e-4) {
+			t.Errorf("[%d] wrapAngle(%f) = %f, want %f", i, test.angle, a, test.expected)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package glm
+
+import (
+	"github.com/EngoEngine/math"
+)
+
+// DualQuat is a dual quaternion, Real + ε*Dual with ε² = 0. Normalized dual
+// quaternions represent rigid transforms, a rotation followed by a
+// translation, the way quaternions represent rotations. Unlike matrices they
+// can be blended without shearing or shrinking, which makes them the usual
+// choice for skinning.
+type DualQuat struct {
+	Real, Dual Quat
+}
+
+// DualQuatIdent returns the identity dual quaternion, the transform that
+// doesn't move anything.
+func DualQuatIdent() DualQuat {
+	return DualQuat{Real: QuatIdent()}
+}
+
+// NewDualQuat returns the dual quaternion of the rotation followed by the
+// translation. rotation must be normalized.
+func NewDualQuat(rotation *Quat, translation *Vec3) DualQuat {
+	t := Quat{0, translation.Mul(0.5)}
+	return DualQuat{Real: *rotation, Dual: t.Mul(rotation)}
+}
+
+// Mat3x4ToDualQuat converts a rigid transform, a rotation followed by a
+// translation, into a dual quaternion.
+func Mat3x4ToDualQuat(m *Mat3x4) DualQuat {
+	rotation := Mat3{m[0], m[1], m[2], m[3], m[4], m[5], m[6], m[7], m[8]}
+	q := Mat3ToQuat(&rotation)
+	return NewDualQuat(&q, &Vec3{m[9], m[10], m[11]})
+}
+
+// TransformToDualQuat converts a rigid transform into a dual quaternion.
+func TransformToDualQuat(t *Transform) DualQuat {
+	m := ((*Mat4)(t)).Mat3x4()
+	return Mat3x4ToDualQuat(&m)
+}
+
+// Rotation returns the rotation of the transform.
+func (d1 *DualQuat) Rotation() Quat {
+	return d1.Real
+}
+
+// Translation returns the translation of the transform, applied after its
+// rotation.
+func (d1 *DualQuat) Translation() Vec3 {
+	conj := d1.Real.Conjugated()
+	t := d1.Dual.Mul(&conj)
+	return t.V.Mul(2)
+}
+
+// Mul multiplies 2 dual quaternions, the transform d2 followed by d1.
+func (d1 *DualQuat) Mul(d2 *DualQuat) DualQuat {
+	a, b := d1.Real.Mul(&d2.Dual), d1.Dual.Mul(&d2.Real)
+	return DualQuat{Real: d1.Real.Mul(&d2.Real), Dual: a.Add(&b)}
+}
+
+// MulWith is a memory friendly version of Mul. d1 = d1 * d2
+func (d1 *DualQuat) MulWith(d2 *DualQuat) {
+	*d1 = d1.Mul(d2)
+}
+
+// Scale returns the dual quaternion with both its parts scaled by c.
+func (d1 *DualQuat) Scale(c float32) DualQuat {
+	return DualQuat{Real: d1.Real.Scale(c), Dual: d1.Dual.Scale(c)}
+}
+
+// Conjugated returns the quaternion conjugate of both parts of the dual
+// quaternion, the inverse transform if it is normalized.
+func (d1 *DualQuat) Conjugated() DualQuat {
+	return DualQuat{Real: d1.Real.Conjugated(), Dual: d1.Dual.Conjugated()}
+}
+
+// Conjugate is a memory friendly version of Conjugated. d1 = conjugate(d1)
+func (d1 *DualQuat) Conjugate() {
+	d1.Real.Conjugate()
+	d1.Dual.Conjugate()
+}
+
+// Normalized returns the normalized dual quaternion: its real part has a
+// length of 1 and is orthogonal to its dual part.
+func (d1 *DualQuat) Normalized() DualQuat {
+	l := d1.Real.Len()
+	if l == 0 {
+		return DualQuatIdent()
+	}
+	r, d := d1.Real.Scale(1/l), d1.Dual.Scale(1/l)
+	p := r.Scale(r.Dot(&d))
+	return DualQuat{Real: r, Dual: d.Sub(&p)}
+}
+
+// Normalize is a memory friendly version of Normalized.
+func (d1 *DualQuat) Normalize() {
+	*d1 = d1.Normalized()
+}
+
+// TransformPoint returns the point p transformed by the normalized dual
+// quaternion.
+func (d1 *DualQuat) TransformPoint(p *Vec3) Vec3 {
+	v := d1.Real.Rotate(p)
+	t := d1.Translation()
+	return v.Add(&t)
+}
+
+// TransformVector returns the vector v transformed by the normalized dual
+// quaternion, which ignores its translation.
+func (d1 *DualQuat) TransformVector(v *Vec3) Vec3 {
+	return d1.Real.Rotate(v)
+}
+
+// Mat3x4 returns the matrix of the transform of the normalized dual
+// quaternion.
+func (d1 *DualQuat) Mat3x4() Mat3x4 {
+	r := d1.Real.Mat3()
+	m := r.Mat3x4()
+	t := d1.Translation()
+	m[9], m[10], m[11] = t[0], t[1], t[2]
+	return m
+}
+
+// Transform returns the transform of the normalized dual quaternion.
+func (d1 *DualQuat) Transform() Transform {
+	m := d1.Mat3x4()
+	return Transform(m.Mat4())
+}
+
+// Dot returns the dot product of the real parts of 2 dual quaternions.
+func (d1 *DualQuat) Dot(d2 *DualQuat) float32 {
+	return d1.Real.Dot(&d2.Real)
+}
+
+// EqualThreshold returns whether the parts of 2 dual quaternions are
+// approximately equal, see Quat.EqualThreshold.
+func (d1 *DualQuat) EqualThreshold(d2 *DualQuat, epsilon float32) bool {
+	return d1.Real.EqualThreshold(&d2.Real, epsilon) && d1.Dual.EqualThreshold(&d2.Dual, epsilon)
+}
+
+// Pow returns the normalized dual quaternion raised to the power t, its
+// screw motion with the angle and the distance multiplied by t.
+func (d1 *DualQuat) Pow(t float32) DualQuat {
+	r, d := d1.Real, d1.Dual
+	if r.W < 0 {
+		r, d = r.Scale(-1), d.Scale(-1)
+	}
+
+	s := r.V.Len()
+	if s < 1e-6 {
+		// A translation.
+		return DualQuat{Real: QuatIdent(), Dual: d.Scale(t)}
+	}
+
+	// The screw of the rotation angle around the axis l through the point
+	// with the moment m, and of the translation dist along l.
+	angle := 2 * math.Atan2(s, r.W)
+	l := r.V.Mul(1 / s)
+	dist := -2 * d.W / s
+	m := d.V
+	m.AddScaledVec(-r.W*dist/2, &l)
+	m.MulWith(1 / s)
+
+	angle, dist = angle*t, dist*t
+	sin, cos := math.Sincos(angle / 2)
+	dual := m.Mul(sin)
+	dual.AddScaledVec(cos*dist/2, &l)
+	return DualQuat{
+		Real: Quat{cos, l.Mul(sin)},
+		Dual: Quat{-sin * dist / 2, dual},
+	}
+}
+
+// DualQuatSclerp is *Sc*rew *L*inear Int*erp*olation between two normalized
+// dual quaternions, the equivalent of QuatSlerp for rigid transforms: the
+// transform moves along a screw, at constant speed. It takes the shortest
+// path, d2 is negated if it isn't in the hemisphere of d1.
+func DualQuatSclerp(d1, d2 *DualQuat, amount float32) DualQuat {
+	end := *d2
+	if d1.Dot(d2) < 0 {
+		end = end.Scale(-1)
+	}
+	inv := d1.Conjugated()
+	diff := inv.Mul(&end)
+	diff = diff.Pow(amount)
+	return d1.Mul(&diff)
+}
+
+// DualQuatBlend is dual quaternion linear blending (DLB), the normalized
+// weighted sum of the dual quaternions. It is much cheaper than
+// DualQuatSclerp and is the way dual quaternion skinning blends the
+// transforms of the bones. Dual quaternions not in the hemisphere of the first
+// one are negated. dqs and weights must have the same length.
+func DualQuatBlend(dqs []DualQuat, weights []float32) DualQuat {
+	if len(dqs) == 0 {
+		return DualQuatIdent()
+	}
+
+	var sum DualQuat
+	for n := range dqs {
+		w := weights[n]
+		if dqs[0].Dot(&dqs[n]) < 0 {
+			w = -w
+		}
+		r, d := dqs[n].Real.Scale(w), dqs[n].Dual.Scale(w)
+		sum.Real.AddWith(&r)
+		sum.Dual.AddWith(&d)
+	}
+	return sum.Normalized()
+}
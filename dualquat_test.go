@@ -0,0 +1,194 @@
+package glm
+
+import (
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+// near returns true if the elements of a and b are within epsilon of each
+// other. Unlike EqualThreshold, it isn't relative, so it works close to 0.
+func near(a, b []float32, epsilon float32) bool {
+	for n := range a {
+		if math.Abs(a[n]-b[n]) > epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+// dualQuatNear returns true if the parts of d1 and d2 are within epsilon of
+// each other.
+func dualQuatNear(d1, d2 *DualQuat, epsilon float32) bool {
+	return near([]float32{d1.Real.W, d1.Real.V[0], d1.Real.V[1], d1.Real.V[2], d1.Dual.W, d1.Dual.V[0], d1.Dual.V[1], d1.Dual.V[2]},
+		[]float32{d2.Real.W, d2.Real.V[0], d2.Real.V[1], d2.Real.V[2], d2.Dual.W, d2.Dual.V[0], d2.Dual.V[1], d2.Dual.V[2]}, epsilon)
+}
+
+// dualQuatTests are rigid transforms, the rotation followed by the
+// translation.
+var dualQuatTests = []struct {
+	rotation    Quat
+	translation Vec3
+}{
+	{QuatIdent(), Vec3{}},
+	{QuatIdent(), Vec3{1, 2, 3}},
+	{QuatRotate(math.Pi/2, &Vec3{0, 1, 0}), Vec3{}},
+	{QuatRotate(1, &Vec3{0.6, 0, 0.8}), Vec3{-4, 0.5, 2}},
+	{AnglesToQuat(0.3, -2, 2.5, XYZ), Vec3{10, -20, 5}},
+}
+
+func TestDualQuat_Transform(t *testing.T) {
+	t.Parallel()
+	points := []Vec3{{0, 0, 0}, {1, 0, 0}, {-2, 3, 5}}
+	for i, test := range dualQuatTests {
+		d := NewDualQuat(&test.rotation, &test.translation)
+		if tr := d.Translation(); !near(tr[:], test.translation[:], 1e-4) {
+			t.Errorf("[%d] translation = %v, want %v", i, tr, test.translation)
+		}
+
+		m := d.Mat3x4()
+		for _, p := range points {
+			expected := test.rotation.Rotate(&p)
+			expected.AddWith(&test.translation)
+			if v := d.TransformPoint(&p); !near(v[:], expected[:], 1e-4) {
+				t.Errorf("[%d] TransformPoint(%v) = %v, want %v", i, p, v, expected)
+			}
+			if v := m.Mul3x1(&p); !near(v[:], expected[:], 1e-4) {
+				t.Errorf("[%d] Mat3x4 transforms %v to %v, want %v", i, p, v, expected)
+			}
+			expected = test.rotation.Rotate(&p)
+			if v := d.TransformVector(&p); !near(v[:], expected[:], 1e-4) {
+				t.Errorf("[%d] TransformVector(%v) = %v, want %v", i, p, v, expected)
+			}
+		}
+
+		// Back from the matrices.
+		tr := d.Transform()
+		for _, r := range []DualQuat{Mat3x4ToDualQuat(&m), TransformToDualQuat(&tr)} {
+			if r.Dot(&d) < 0 {
+				r = r.Scale(-1)
+			}
+			if !dualQuatNear(&r, &d, 1e-4) {
+				t.Errorf("[%d] dual quaternion of the matrix = %v, want %v", i, r, d)
+			}
+		}
+	}
+}
+
+func TestDualQuat_Mul(t *testing.T) {
+	t.Parallel()
+	for i, test1 := range dualQuatTests {
+		for j, test2 := range dualQuatTests {
+			d1 := NewDualQuat(&test1.rotation, &test1.translation)
+			d2 := NewDualQuat(&test2.rotation, &test2.translation)
+			m1, m2 := d1.Mat3x4(), d2.Mat3x4()
+
+			d := d1.Mul(&d2)
+			m := d.Mat3x4()
+			if expected := m1.Mul3x4(&m2); !near(m[:], expected[:], 1e-4) {
+				t.Errorf("[%d, %d] d1*d2 =\n%swant\n%s", i, j, m.String(), expected.String())
+			}
+
+			// The conjugate is the inverse.
+			conj := d1.Conjugated()
+			d = d1.Mul(&conj)
+			if iden := DualQuatIdent(); !dualQuatNear(&d, &iden, 1e-4) {
+				t.Errorf("[%d] d*conjugate(d) = %v, want identity", i, d)
+			}
+		}
+	}
+}
+
+func TestDualQuat_Normalize(t *testing.T) {
+	t.Parallel()
+	for i, test := range dualQuatTests {
+		d := NewDualQuat(&test.rotation, &test.translation)
+		// Scaled, with a dual part not orthogonal to the real part.
+		s := d.Scale(3)
+		p := d.Real.Scale(0.3)
+		s.Dual.AddWith(&p)
+		s.Normalize()
+		if !dualQuatNear(&s, &d, 1e-4) {
+			t.Errorf("[%d] normalized = %v, want %v", i, s, d)
+		}
+		if l := s.Real.Len(); !FloatEqualThreshold(l, 1, 1e-4) {
+			t.Errorf("[%d] length of the real part = %f, want 1", i, l)
+		}
+		if dot := s.Real.Dot(&s.Dual); math.Abs(dot) > 1e-4 {
+			t.Errorf("[%d] real.dual = %f, want 0", i, dot)
+		}
+	}
+}
+
+func TestDualQuatSclerp(t *testing.T) {
+	t.Parallel()
+	axis := Vec3{0, 1, 0}
+
+	// A rotation around the vertical axis through {1, 0, 0} while moving up.
+	screw := func(angle, height float32) DualQuat {
+		q := QuatRotate(angle, &axis)
+		center := Vec3{1, 0, 0}
+		tr := q.Rotate(&center)
+		tr = center.Sub(&tr)
+		tr[1] = height
+		return NewDualQuat(&q, &tr)
+	}
+	end := screw(2, 4)
+
+	tests := []struct {
+		d1, d2   DualQuat
+		amount   float32
+		expected DualQuat
+	}{
+		{screw(0, 0), screw(2, 4), 0, screw(0, 0)},
+		{screw(0, 0), screw(2, 4), 1, screw(2, 4)},
+		{screw(0, 0), screw(2, 4), 0.5, screw(1, 2)},
+		{screw(0, 0), screw(2, 4), 0.25, screw(0.5, 1)},
+		{screw(1, -1), screw(2, 4), 0.4, screw(1.4, 1)},
+		// The shortest path.
+		{screw(0, 0), end.Scale(-1), 0.5, screw(1, 2)},
+		{screw(-3, 0), screw(3, 0), 0.5, screw(math.Pi, 0)},
+		// Translations.
+		{NewDualQuat(&Quat{W: 1}, &Vec3{1, 2, 3}), NewDualQuat(&Quat{W: 1}, &Vec3{3, 2, -1}), 0.5, NewDualQuat(&Quat{W: 1}, &Vec3{2, 2, 1})},
+	}
+
+	for i, test := range tests {
+		d := DualQuatSclerp(&test.d1, &test.d2, test.amount)
+		if d.Dot(&test.expected) < 0 {
+			d = d.Scale(-1)
+		}
+		if !dualQuatNear(&d, &test.expected, 1e-4) {
+			t.Errorf("[%d] sclerp = %v, want %v", i, d, test.expected)
+		}
+	}
+}
+
+func TestDualQuatBlend(t *testing.T) {
+	t.Parallel()
+	d1 := NewDualQuat(&Quat{W: 1}, &Vec3{1, 0, 0})
+	q := QuatRotate(1, &Vec3{0, 0, 1})
+	d2 := NewDualQuat(&q, &Vec3{1, 0, 0})
+	half := QuatRotate(0.5, &Vec3{0, 0, 1})
+
+	tests := []struct {
+		dqs      []DualQuat
+		weights  []float32
+		expected DualQuat
+	}{
+		{nil, nil, DualQuatIdent()},
+		{[]DualQuat{d1, d2}, []float32{1, 0}, d1},
+		{[]DualQuat{d1, d2}, []float32{0, 2}, d2},
+		{[]DualQuat{d1, d2}, []float32{0.5, 0.5}, NewDualQuat(&half, &Vec3{1, 0, 0})},
+		{[]DualQuat{d1, d2.Scale(-1)}, []float32{0.5, 0.5}, NewDualQuat(&half, &Vec3{1, 0, 0})},
+		{[]DualQuat{d2, d2, d2}, []float32{0.2, 0.3, 0.5}, d2},
+	}
+
+	for i, test := range tests {
+		d := DualQuatBlend(test.dqs, test.weights)
+		if d.Dot(&test.expected) < 0 {
+			d = d.Scale(-1)
+		}
+		if !dualQuatNear(&d, &test.expected, 1e-4) {
+			t.Errorf("[%d] blend = %v, want %v", i, d, test.expected)
+		}
+	}
+}
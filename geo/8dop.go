@@ -2,6 +2,7 @@ package geo
 
 import (
 	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
 )
 
 // DOP8 is an 8-DOP.
@@ -10,6 +11,26 @@ type DOP8 struct {
 	Max [4]float32
 }
 
+// dop8Axes are the axes of the 8-DOP, in the same order as Min and Max.
+var dop8Axes = [4]glm.Vec3{{1, 1, 1}, {1, 1, -1}, {1, -1, 1}, {-1, 1, 1}}
+
+// dop8Inverses[k] is the inverse of the matrix whose rows are all the axes of
+// the 8-DOP but axis k. It is used to intersect the planes of the 8-DOP.
+var dop8Inverses = func() (inverses [4]glm.Mat3) {
+	for k := range inverses {
+		var rows [3]glm.Vec3
+		for i, n := 0, 0; i < 4; i++ {
+			if i != k {
+				rows[n] = dop8Axes[i]
+				n++
+			}
+		}
+		m := glm.Mat3FromRows(&rows[0], &rows[1], &rows[2])
+		inverses[k] = m.Inverse()
+	}
+	return
+}()
+
 // TestDOP8DOP8 returns true if the 8-DOP intersect.
 func TestDOP8DOP8(a, b *DOP8) bool {
 	for n := 0; n < 4; n++ {
@@ -61,3 +82,42 @@ func DOP8FromPoints(d *DOP8, points []glm.Vec3) {
 		}
 	}
 }
+
+// Support returns the vertex of the 8-DOP furthest along direction. The
+// vertices are found by intersecting the planes of the 8-DOP 3 at a time.
+func (d *DOP8) Support(direction glm.Vec3) glm.Vec3 {
+	// The intersections go through an inverse matrix, the tolerance keeps the
+	// vertices on the planes of the slab despite rounding errors.
+	const epsilon = 0.0001
+
+	var best glm.Vec3
+	bestDot := float32(-math.MaxFloat32)
+	for k := 0; k < 4; k++ {
+		for corner := uint(0); corner < 8; corner++ {
+			// Pick the min or max plane of every axis but k.
+			var planes glm.Vec3
+			for i, n := 0, uint(0); i < 4; i++ {
+				if i == k {
+					continue
+				}
+				if corner&(1<<n) == 0 {
+					planes[n] = d.Min[i]
+				} else {
+					planes[n] = d.Max[i]
+				}
+				n++
+			}
+			v := dop8Inverses[k].Mul3x1(&planes)
+
+			// Only keep the intersection if it's inside the slab of axis k.
+			if proj := dop8Axes[k].Dot(&v); proj < d.Min[k]-epsilon || proj > d.Max[k]+epsilon {
+				continue
+			}
+			if dot := v.Dot(&direction); dot > bestDot {
+				bestDot = dot
+				best = v
+			}
+		}
+	}
+	return best
+}
@@ -83,3 +83,16 @@ func SqDistAABBPoint(a *AABB, p *glm.Vec3) float32 {
 
 	return sqDist
 }
+
+// Support returns the point of the AABB furthest along direction.
+func (a *AABB) Support(direction glm.Vec3) glm.Vec3 {
+	p := a.Center
+	for i := 0; i < 3; i++ {
+		if direction[i] < 0 {
+			p[i] -= a.HalfExtend[i]
+		} else {
+			p[i] += a.HalfExtend[i]
+		}
+	}
+	return p
+}
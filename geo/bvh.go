@@ -0,0 +1,354 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+const (
+	// bvhBins is the amount of buckets the centroids are sorted in to evaluate
+	// the surface area heuristic.
+	bvhBins = 16
+
+	// bvhMinLeafSize is the amount of triangles under which a node is always a
+	// leaf.
+	bvhMinLeafSize = 4
+
+	// bvhMaxLeafSize is the maximum amount of triangles in a leaf, unless
+	// their centroids are all the same.
+	bvhMaxLeafSize = 16
+)
+
+// BVH is a static bounding volume hierarchy over a triangle soup, built with
+// the surface area heuristic. The nodes are stored depth first in a flat
+// array.
+type BVH struct {
+	// the triangle soup, triangle i is {3i 3i+1 3i+2}.
+	vertices []glm.Vec3
+
+	// the triangle indices, sorted so every leaf refers to a contiguous range.
+	triangles []int
+
+	nodes []bvhNode
+}
+
+// bvhNode is a node of a BVH. The left child of an internal node is the next
+// node in the array.
+type bvhNode struct {
+	aabb AABB
+
+	// for leaves, the range of the triangles of the leaf in BVH.triangles.
+	// For internal nodes count is 0 and offset is the index of the right
+	// child.
+	offset, count int
+}
+
+// bvhBounds is an AABB stored as min and max, used while building the BVH.
+type bvhBounds struct {
+	min, max glm.Vec3
+}
+
+// emptyBounds returns bounds that contain nothing.
+func emptyBounds() bvhBounds {
+	return bvhBounds{
+		min: glm.Vec3{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32},
+		max: glm.Vec3{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32},
+	}
+}
+
+// grow extends the bounds to contain p.
+func (b *bvhBounds) grow(p *glm.Vec3) {
+	for i := 0; i < 3; i++ {
+		b.min[i] = math.Min(b.min[i], p[i])
+		b.max[i] = math.Max(b.max[i], p[i])
+	}
+}
+
+// merge extends the bounds to contain c.
+func (b *bvhBounds) merge(c *bvhBounds) {
+	b.grow(&c.min)
+	b.grow(&c.max)
+}
+
+// area returns the surface area of the bounds, 0 if they are empty.
+func (b *bvhBounds) area() float32 {
+	d := b.max.Sub(&b.min)
+	if d[0] < 0 {
+		return 0
+	}
+	return 2 * (d[0]*d[1] + d[1]*d[2] + d[2]*d[0])
+}
+
+// aabb converts the bounds to an AABB.
+func (b *bvhBounds) aabb() AABB {
+	center, extend := b.min.Add(&b.max), b.max.Sub(&b.min)
+	return AABB{Center: center.Mul(0.5), HalfExtend: extend.Mul(0.5)}
+}
+
+// NewBVH builds a BVH over the triangle soup, triangle i is made of
+// vertices[3i], vertices[3i+1] and vertices[3i+2]. Trailing vertices that
+// don't form a triangle are ignored. The BVH keeps a reference to vertices,
+// they must not be modified.
+func NewBVH(vertices []glm.Vec3) *BVH {
+	b := &BVH{
+		vertices:  vertices,
+		triangles: make([]int, len(vertices)/3),
+	}
+
+	bounds := make([]bvhBounds, len(b.triangles))
+	centroids := make([]glm.Vec3, len(b.triangles))
+	for n := range b.triangles {
+		b.triangles[n] = n
+		bounds[n] = emptyBounds()
+		for m := 0; m < 3; m++ {
+			bounds[n].grow(&vertices[3*n+m])
+			centroids[n].AddWith(&vertices[3*n+m])
+		}
+		centroids[n].MulWith(1.0 / 3)
+	}
+
+	if len(b.triangles) > 0 {
+		b.build(0, len(b.triangles), bounds, centroids)
+	}
+	return b
+}
+
+// build creates the node for the triangles [first, first+count) and its
+// children. It returns the index of the node.
+func (b *BVH) build(first, count int, bounds []bvhBounds, centroids []glm.Vec3) int {
+	index := len(b.nodes)
+	b.nodes = append(b.nodes, bvhNode{offset: first, count: count})
+
+	nodeBounds, centroidBounds := emptyBounds(), emptyBounds()
+	for _, tri := range b.triangles[first : first+count] {
+		nodeBounds.merge(&bounds[tri])
+		centroidBounds.grow(&centroids[tri])
+	}
+	b.nodes[index].aabb = nodeBounds.aabb()
+	if count <= bvhMinLeafSize {
+		return index
+	}
+
+	axis, split, cost := b.findSplit(first, count, bounds, centroids, &centroidBounds)
+	if axis < 0 || (cost >= float32(count) && count <= bvhMaxLeafSize) {
+		return index
+	}
+
+	// Partition the triangles on both sides of the split.
+	mid := first
+	for n := first; n < first+count; n++ {
+		if centroids[b.triangles[n]][axis] < split {
+			b.triangles[n], b.triangles[mid] = b.triangles[mid], b.triangles[n]
+			mid++
+		}
+	}
+	if mid == first || mid == first+count {
+		return index
+	}
+
+	b.build(first, mid-first, bounds, centroids)
+	right := b.build(mid, first+count-mid, bounds, centroids)
+	b.nodes[index].offset, b.nodes[index].count = right, 0
+	return index
+}
+
+// findSplit returns the axis and position of the plane that splits the
+// triangles with the lowest cost according to the surface area heuristic.
+// The cost is relative to the cost of intersecting a triangle. axis is -1 if
+// the centroids can't be split.
+func (b *BVH) findSplit(first, count int, bounds []bvhBounds, centroids []glm.Vec3, centroidBounds *bvhBounds) (axis int, split, cost float32) {
+	axis, cost = -1, math.MaxFloat32
+
+	var nodeBounds bvhBounds
+	for i := 0; i < 3; i++ {
+		min, max := centroidBounds.min[i], centroidBounds.max[i]
+		if max <= min {
+			continue
+		}
+
+		// Sort the triangles in bins.
+		var bins [bvhBins]bvhBounds
+		var counts [bvhBins]int
+		for n := range bins {
+			bins[n] = emptyBounds()
+		}
+		scale := bvhBins / (max - min)
+		nodeBounds = emptyBounds()
+		for _, tri := range b.triangles[first : first+count] {
+			bin := int((centroids[tri][i] - min) * scale)
+			if bin >= bvhBins {
+				bin = bvhBins - 1
+			}
+			bins[bin].merge(&bounds[tri])
+			counts[bin]++
+			nodeBounds.merge(&bounds[tri])
+		}
+
+		// Sweep from the right to get the area and count of every suffix,
+		// then from the left to evaluate every split.
+		var rightAreas [bvhBins]float32
+		var rightCounts [bvhBins]int
+		right := emptyBounds()
+		for n, total := bvhBins-1, 0; n > 0; n-- {
+			right.merge(&bins[n])
+			total += counts[n]
+			rightAreas[n], rightCounts[n] = right.area(), total
+		}
+
+		left := emptyBounds()
+		var leftCount int
+		nodeArea := nodeBounds.area()
+		for n := 1; n < bvhBins; n++ {
+			left.merge(&bins[n-1])
+			leftCount += counts[n-1]
+			if leftCount == 0 || rightCounts[n] == 0 {
+				continue
+			}
+			c := 1 + (left.area()*float32(leftCount)+rightAreas[n]*float32(rightCounts[n]))/nodeArea
+			if c < cost {
+				axis, split, cost = i, min+float32(n)/scale, c
+			}
+		}
+	}
+	return
+}
+
+// triangle returns the vertices of triangle n.
+func (b *BVH) triangle(n int) (v0, v1, v2 *glm.Vec3) {
+	return &b.vertices[3*n], &b.vertices[3*n+1], &b.vertices[3*n+2]
+}
+
+// Raycast returns the closest hit of the ray with the front face, CCW, of a
+// triangle and the index of that triangle. maxT must be finite.
+func (b *BVH) Raycast(r *Ray, maxT float32) (hit Hit, triangle int, ok bool) {
+	triangle = -1
+	b.raycast(r, maxT, func(tri int, h *Hit) float32 {
+		hit, triangle, ok = *h, tri, true
+		return h.T
+	})
+	return
+}
+
+// RaycastAny returns true if the ray hits the front face, CCW, of any triangle
+// before maxT. It stops at the first hit found, which is not necessarily the
+// closest. maxT must be finite.
+func (b *BVH) RaycastAny(r *Ray, maxT float32) bool {
+	var ok bool
+	b.raycast(r, maxT, func(tri int, h *Hit) float32 {
+		ok = true
+		return -1
+	})
+	return ok
+}
+
+// bvhStackEntry is a node waiting to be visited by a ray and where the ray
+// enters its AABB.
+type bvhStackEntry struct {
+	node int
+	t    float32
+}
+
+// raycast traverses the BVH closest node first and calls callback with every
+// triangle hit before maxT. callback returns the new maxT, a negative value
+// stops the traversal.
+func (b *BVH) raycast(r *Ray, maxT float32, callback func(triangle int, h *Hit) float32) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	h, ok := RaycastAABB(r, &b.nodes[0].aabb, maxT)
+	if !ok {
+		return
+	}
+
+	stack := []bvhStackEntry{{0, h.T}}
+	for len(stack) > 0 {
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if entry.t > maxT {
+			continue
+		}
+
+		node := &b.nodes[entry.node]
+		if node.count == 0 {
+			// Push the farthest child first so the closest is visited first.
+			near, far := entry.node+1, node.offset
+			hn, okn := RaycastAABB(r, &b.nodes[near].aabb, maxT)
+			hf, okf := RaycastAABB(r, &b.nodes[far].aabb, maxT)
+			if okn && okf && hf.T < hn.T {
+				near, far, hn, hf = far, near, hf, hn
+			}
+			if okf {
+				stack = append(stack, bvhStackEntry{far, hf.T})
+			}
+			if okn {
+				stack = append(stack, bvhStackEntry{near, hn.T})
+			}
+			continue
+		}
+
+		q := r.At(maxT)
+		for _, tri := range b.triangles[node.offset : node.offset+node.count] {
+			v0, v1, v2 := b.triangle(tri)
+			_, _, _, t, ok := IntersectSegmentTriangle2(&r.Origin, &q, v0, v1, v2)
+			if !ok {
+				continue
+			}
+			e0, e1 := v1.Sub(v0), v2.Sub(v0)
+			h := Hit{T: t * maxT, Normal: e0.Cross(&e1)}
+			h.Point = r.At(h.T)
+			h.Normal.Normalize()
+			if maxT = callback(tri, &h); maxT < 0 {
+				return
+			}
+			q = r.At(maxT)
+		}
+	}
+}
+
+// QuerySphere calls callback with the index of every triangle that intersects
+// the sphere. The query stops if callback returns false.
+func (b *BVH) QuerySphere(s *Sphere, callback func(triangle int) bool) {
+	b.query(func(aabb *AABB) bool {
+		return TestSphereAABB(s, aabb)
+	}, func(v0, v1, v2 *glm.Vec3) bool {
+		return TestSphereTriangle(s, v0, v1, v2)
+	}, callback)
+}
+
+// QueryAABB calls callback with the index of every triangle that intersects
+// the AABB. The query stops if callback returns false.
+func (b *BVH) QueryAABB(a *AABB, callback func(triangle int) bool) {
+	b.query(func(aabb *AABB) bool {
+		return TestAABBAABB(a, aabb)
+	}, func(v0, v1, v2 *glm.Vec3) bool {
+		return TestTriangleAABB(v0, v1, v2, a)
+	}, callback)
+}
+
+// query traverses the nodes that pass testNode and calls callback with the
+// triangles that pass testTriangle.
+func (b *BVH) query(testNode func(aabb *AABB) bool, testTriangle func(v0, v1, v2 *glm.Vec3) bool, callback func(triangle int) bool) {
+	if len(b.nodes) == 0 {
+		return
+	}
+
+	stack := []int{0}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &b.nodes[index]
+		if !testNode(&node.aabb) {
+			continue
+		}
+		if node.count == 0 {
+			stack = append(stack, node.offset, index+1)
+			continue
+		}
+		for _, tri := range b.triangles[node.offset : node.offset+node.count] {
+			if testTriangle(b.triangle(tri)) && !callback(tri) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// randomTriangles returns a soup of n small random triangles.
+func randomTriangles(r *rand.Rand, n int) []glm.Vec3 {
+	vertices := make([]glm.Vec3, 0, 3*n)
+	for i := 0; i < n; i++ {
+		center := glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}
+		for m := 0; m < 3; m++ {
+			v := glm.Vec3{r.Float32()*10 - 5, r.Float32()*10 - 5, r.Float32()*10 - 5}
+			vertices = append(vertices, center.Add(&v))
+		}
+	}
+	return vertices
+}
+
+func TestBVH_Raycast(t *testing.T) {
+	t.Parallel()
+	// A unit quad in the z=0 plane facing +z and one in z=-5 facing -z.
+	vertices := []glm.Vec3{
+		{0, 0, 0}, {1, 0, 0}, {1, 1, 0},
+		{0, 0, 0}, {1, 1, 0}, {0, 1, 0},
+		{0, 0, -5}, {1, 1, -5}, {1, 0, -5},
+		{0, 0, -5}, {0, 1, -5}, {1, 1, -5},
+	}
+	bvh := NewBVH(vertices)
+
+	tests := []struct {
+		ray    Ray
+		maxT   float32
+		ok     bool
+		t      float32
+		normal glm.Vec3
+	}{
+		{Ray{glm.Vec3{0.5, 0.25, 1}, glm.Vec3{0, 0, -1}}, 10, true, 1, glm.Vec3{0, 0, 1}},
+		{Ray{glm.Vec3{0.5, 0.75, 2}, glm.Vec3{0, 0, -2}}, 10, true, 1, glm.Vec3{0, 0, 1}},
+		{Ray{glm.Vec3{0.5, 0.25, 1}, glm.Vec3{0, 0, -1}}, 0.5, false, 0, glm.Vec3{}},
+		{Ray{glm.Vec3{2, 0.25, 1}, glm.Vec3{0, 0, -1}}, 10, false, 0, glm.Vec3{}},
+		// Back faces aren't hit.
+		{Ray{glm.Vec3{0.5, 0.25, -1}, glm.Vec3{0, 0, 1}}, 10, false, 0, glm.Vec3{}},
+		{Ray{glm.Vec3{0.5, 0.25, -1}, glm.Vec3{0, 0, -1}}, 10, false, 0, glm.Vec3{}},
+		{Ray{glm.Vec3{0.5, 0.25, -10}, glm.Vec3{0, 0, 1}}, 10, true, 5, glm.Vec3{0, 0, -1}},
+	}
+
+	for i, test := range tests {
+		h, tri, ok := bvh.Raycast(&test.ray, test.maxT)
+		if ok != test.ok {
+			t.Errorf("[%d] ok = %t, want %t", i, ok, test.ok)
+			continue
+		}
+		if hitAny := bvh.RaycastAny(&test.ray, test.maxT); hitAny != test.ok {
+			t.Errorf("[%d] RaycastAny = %t, want %t", i, hitAny, test.ok)
+		}
+		if !ok {
+			if tri != -1 {
+				t.Errorf("[%d] triangle = %d, want -1", i, tri)
+			}
+			continue
+		}
+		if !glm.FloatEqualThreshold(h.T, test.t, 1e-4) {
+			t.Errorf("[%d] T = %f, want %f", i, h.T, test.t)
+		}
+		if !h.Normal.EqualThreshold(&test.normal, 1e-4) {
+			t.Errorf("[%d] normal = %v, want %v", i, h.Normal, test.normal)
+		}
+		if p := test.ray.At(h.T); !h.Point.EqualThreshold(&p, 1e-4) {
+			t.Errorf("[%d] point = %v, want %v", i, h.Point, p)
+		}
+	}
+}
+
+func TestBVH_BruteForce(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	vertices := randomTriangles(r, 500)
+	bvh := NewBVH(vertices)
+	triangles := len(vertices) / 3
+
+	for n := 0; n < 200; n++ {
+		ray := Ray{
+			Origin:    glm.Vec3{r.Float32()*120 - 60, r.Float32()*120 - 60, r.Float32()*120 - 60},
+			Direction: glm.Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1},
+		}
+		const maxT = 100
+
+		// The closest front face hit by brute force.
+		wantT, wantTri := float32(maxT), -1
+		for tri := 0; tri < triangles; tri++ {
+			v0, v1, v2 := &vertices[3*tri], &vertices[3*tri+1], &vertices[3*tri+2]
+			e0, e1 := v1.Sub(v0), v2.Sub(v0)
+			normal := e0.Cross(&e1)
+			if normal.Dot(&ray.Direction) >= 0 {
+				continue
+			}
+			if h, ok := RaycastTriangle(&ray, v0, v1, v2, maxT); ok && h.T < wantT {
+				wantT, wantTri = h.T, tri
+			}
+		}
+
+		h, tri, ok := bvh.Raycast(&ray, maxT)
+		if ok != (wantTri >= 0) {
+			t.Errorf("[%d] Raycast ok = %t, want %t", n, ok, wantTri >= 0)
+			continue
+		}
+		if hitAny := bvh.RaycastAny(&ray, maxT); hitAny != ok {
+			t.Errorf("[%d] RaycastAny = %t, want %t", n, hitAny, ok)
+		}
+		if !ok {
+			continue
+		}
+		// Triangles sharing an edge can both be hit at the closest distance,
+		// the triangle only has to be hit there.
+		if !glm.FloatEqualThreshold(h.T, wantT, 1e-4) {
+			t.Errorf("[%d] Raycast = %d at %f, want %d at %f", n, tri, h.T, wantTri, wantT)
+		}
+		v0, v1, v2 := &vertices[3*tri], &vertices[3*tri+1], &vertices[3*tri+2]
+		if th, hit := RaycastTriangle(&ray, v0, v1, v2, maxT); !hit || !glm.FloatEqualThreshold(th.T, h.T, 1e-4) {
+			t.Errorf("[%d] Raycast = %d at %f, but the triangle is hit at %f, %t", n, tri, h.T, th.T, hit)
+		}
+	}
+
+	for n := 0; n < 200; n++ {
+		s := Sphere{
+			Center: glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50},
+			Radius: r.Float32() * 10,
+		}
+		a := randomAABB(r)
+
+		var wantSphere, wantAABB []int
+		for tri := 0; tri < triangles; tri++ {
+			v0, v1, v2 := &vertices[3*tri], &vertices[3*tri+1], &vertices[3*tri+2]
+			if TestSphereTriangle(&s, v0, v1, v2) {
+				wantSphere = append(wantSphere, tri)
+			}
+			if TestTriangleAABB(v0, v1, v2, &a) {
+				wantAABB = append(wantAABB, tri)
+			}
+		}
+
+		var gotSphere, gotAABB []int
+		bvh.QuerySphere(&s, func(tri int) bool {
+			gotSphere = append(gotSphere, tri)
+			return true
+		})
+		bvh.QueryAABB(&a, func(tri int) bool {
+			gotAABB = append(gotAABB, tri)
+			return true
+		})
+		sort.Ints(gotSphere)
+		sort.Ints(gotAABB)
+		if !equalInts(gotSphere, wantSphere) {
+			t.Errorf("[%d] QuerySphere = %v, want %v", n, gotSphere, wantSphere)
+		}
+		if !equalInts(gotAABB, wantAABB) {
+			t.Errorf("[%d] QueryAABB = %v, want %v", n, gotAABB, wantAABB)
+		}
+	}
+}
+
+func TestBVH_Empty(t *testing.T) {
+	t.Parallel()
+	bvh := NewBVH(nil)
+	ray := Ray{Direction: glm.Vec3{0, 0, 1}}
+	if _, _, ok := bvh.Raycast(&ray, 10); ok {
+		t.Errorf("Raycast on an empty BVH hit")
+	}
+	bvh.QueryAABB(&AABB{HalfExtend: glm.Vec3{1, 1, 1}}, func(int) bool {
+		t.Errorf("QueryAABB on an empty BVH found a triangle")
+		return true
+	})
+}
+
+func BenchmarkBVH_Raycast(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	bvh := NewBVH(randomTriangles(r, 10000))
+	rays := make([]Ray, 1024)
+	for n := range rays {
+		rays[n] = Ray{
+			Origin:    glm.Vec3{r.Float32()*120 - 60, r.Float32()*120 - 60, r.Float32()*120 - 60},
+			Direction: glm.Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1},
+		}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		bvh.Raycast(&rays[n%len(rays)], 100)
+	}
+}
@@ -2,6 +2,7 @@ package geo
 
 import (
 	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
 )
 
 // Capsule is a cylinder with round end or can be used as a swept sphere.
@@ -24,3 +25,15 @@ func TestCapsuleSphere(c *Capsule, s *Sphere) bool {
 	r := s.Radius + c.Radius
 	return dist2 <= r*r
 }
+
+// Support returns the point of the capsule furthest along direction.
+func (c *Capsule) Support(direction glm.Vec3) glm.Vec3 {
+	p := c.A
+	if direction.Dot(&c.B) > direction.Dot(&c.A) {
+		p = c.B
+	}
+	if l2 := direction.Len2(); l2 > 0 {
+		p.AddScaledVec(c.Radius/math.Sqrt(l2), &direction)
+	}
+	return p
+}
@@ -0,0 +1,412 @@
+package geo
+
+import (
+	"github.com/EngoEngine/math"
+)
+
+// nullNode is the index of a node that doesn't exist.
+const nullNode = -1
+
+// DynamicTree is a bounding volume tree of AABB that can be updated
+// incrementally. Every proxy in the tree is stored with a fat AABB, its AABB
+// grown by a margin, so small moves don't need to update the tree. The tree
+// is kept balanced with rotations.
+type DynamicTree struct {
+	nodes []treeNode
+	root  int
+
+	// the head of the list of free nodes.
+	free int
+
+	// the distance by which the AABB of the proxies are grown.
+	margin float32
+}
+
+// treeNode is a node of a DynamicTree. Leaves are proxies.
+type treeNode struct {
+	// the fat AABB of the proxy for leaves, the union of the children for
+	// internal nodes.
+	aabb AABB
+
+	// the user data of the proxy.
+	data interface{}
+
+	parent, left, right int
+
+	// next is the next free node when the node is in the free list.
+	next int
+
+	// 0 for leaves, -1 for free nodes.
+	height int
+}
+
+func (n *treeNode) isLeaf() bool {
+	return n.left == nullNode
+}
+
+// NewDynamicTree returns an empty tree whose proxies have their AABB grown by
+// margin.
+func NewDynamicTree(margin float32) *DynamicTree {
+	return &DynamicTree{
+		root:   nullNode,
+		free:   nullNode,
+		margin: margin,
+	}
+}
+
+// Insert adds a proxy for aabb to the tree and returns its id. data can be
+// retrieved with Data.
+func (t *DynamicTree) Insert(aabb *AABB, data interface{}) int {
+	id := t.allocate()
+	t.nodes[id].aabb = t.fatten(aabb)
+	t.nodes[id].data = data
+	t.insertLeaf(id)
+	return id
+}
+
+// Remove removes the proxy from the tree. Its id might be reused by a future
+// call to Insert.
+func (t *DynamicTree) Remove(id int) {
+	t.removeLeaf(id)
+	t.release(id)
+}
+
+// Move updates the AABB of the proxy. The tree is only updated if aabb isn't
+// contained in the fat AABB of the proxy anymore, in which case Move returns
+// true.
+func (t *DynamicTree) Move(id int, aabb *AABB) bool {
+	if aabbContains(&t.nodes[id].aabb, aabb) {
+		return false
+	}
+	t.removeLeaf(id)
+	t.nodes[id].aabb = t.fatten(aabb)
+	t.insertLeaf(id)
+	return true
+}
+
+// FatAABB returns the fat AABB of the proxy.
+func (t *DynamicTree) FatAABB(id int) AABB {
+	return t.nodes[id].aabb
+}
+
+// Data returns the user data of the proxy.
+func (t *DynamicTree) Data(id int) interface{} {
+	return t.nodes[id].data
+}
+
+// Height returns the height of the tree, 0 if it's empty or has 1 proxy.
+func (t *DynamicTree) Height() int {
+	if t.root == nullNode {
+		return 0
+	}
+	return t.nodes[t.root].height
+}
+
+// QueryAABB calls callback with the id of every proxy whose fat AABB overlaps
+// aabb. The query stops if callback returns false.
+func (t *DynamicTree) QueryAABB(aabb *AABB, callback func(id int) bool) {
+	if t.root == nullNode {
+		return
+	}
+	stack := []int{t.root}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &t.nodes[index]
+		if !TestAABBAABB(&node.aabb, aabb) {
+			continue
+		}
+		if node.isLeaf() {
+			if !callback(index) {
+				return
+			}
+			continue
+		}
+		stack = append(stack, node.left, node.right)
+	}
+}
+
+// QueryRay calls callback with the id of every proxy whose fat AABB is hit by
+// the ray before maxT. callback returns the new maxT, usually the t at which
+// the ray hits the object, to clip the ray. The query stops if it returns a
+// negative value.
+func (t *DynamicTree) QueryRay(r *Ray, maxT float32, callback func(id int, maxT float32) float32) {
+	if t.root == nullNode {
+		return
+	}
+	stack := []int{t.root}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &t.nodes[index]
+		if _, ok := RaycastAABB(r, &node.aabb, maxT); !ok {
+			continue
+		}
+		if node.isLeaf() {
+			if maxT = callback(index, maxT); maxT < 0 {
+				return
+			}
+			continue
+		}
+		stack = append(stack, node.left, node.right)
+	}
+}
+
+// QueryPairs calls callback once for every pair of proxies whose fat AABB
+// overlap, with a < b.
+func (t *DynamicTree) QueryPairs(callback func(a, b int)) {
+	for n := range t.nodes {
+		if t.nodes[n].height != 0 {
+			continue
+		}
+		t.QueryAABB(&t.nodes[n].aabb, func(id int) bool {
+			if id > n {
+				callback(n, id)
+			}
+			return true
+		})
+	}
+}
+
+// fatten returns aabb grown by the margin of the tree.
+func (t *DynamicTree) fatten(aabb *AABB) AABB {
+	fat := *aabb
+	for i := 0; i < 3; i++ {
+		fat.HalfExtend[i] += t.margin
+	}
+	return fat
+}
+
+// allocate returns the index of an unused node.
+func (t *DynamicTree) allocate() int {
+	id := t.free
+	if id == nullNode {
+		t.nodes = append(t.nodes, treeNode{})
+		id = len(t.nodes) - 1
+	} else {
+		t.free = t.nodes[id].next
+	}
+	t.nodes[id] = treeNode{parent: nullNode, left: nullNode, right: nullNode, next: nullNode}
+	return id
+}
+
+// release puts the node back in the free list.
+func (t *DynamicTree) release(id int) {
+	t.nodes[id] = treeNode{next: t.free, height: -1}
+	t.free = id
+}
+
+// insertLeaf inserts the leaf in the tree, next to the sibling that increases
+// the surface area of the tree the least.
+func (t *DynamicTree) insertLeaf(leaf int) {
+	if t.root == nullNode {
+		t.root = leaf
+		t.nodes[leaf].parent = nullNode
+		return
+	}
+
+	// Find the best sibling.
+	leafAABB := t.nodes[leaf].aabb
+	index := t.root
+	for !t.nodes[index].isLeaf() {
+		node := &t.nodes[index]
+		area := aabbArea(&node.aabb)
+		combined := aabbUnion(&node.aabb, &leafAABB)
+		combinedArea := aabbArea(&combined)
+
+		// Cost of creating a new parent for this node and the new leaf.
+		cost := 2 * combinedArea
+
+		// Minimum cost of pushing the leaf further down the tree.
+		inheritanceCost := 2 * (combinedArea - area)
+		costLeft := t.descendCost(node.left, &leafAABB) + inheritanceCost
+		costRight := t.descendCost(node.right, &leafAABB) + inheritanceCost
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+		if costLeft < costRight {
+			index = node.left
+		} else {
+			index = node.right
+		}
+	}
+	sibling := index
+
+	// Create a new parent.
+	oldParent := t.nodes[sibling].parent
+	newParent := t.allocate()
+	t.nodes[newParent].parent = oldParent
+	t.nodes[newParent].aabb = aabbUnion(&leafAABB, &t.nodes[sibling].aabb)
+	t.nodes[newParent].height = t.nodes[sibling].height + 1
+	t.nodes[newParent].left = sibling
+	t.nodes[newParent].right = leaf
+	t.nodes[sibling].parent = newParent
+	t.nodes[leaf].parent = newParent
+	t.replaceChild(oldParent, sibling, newParent)
+
+	t.refit(t.nodes[leaf].parent)
+}
+
+// descendCost returns the cost of inserting aabb under the node.
+func (t *DynamicTree) descendCost(index int, aabb *AABB) float32 {
+	node := &t.nodes[index]
+	combined := aabbUnion(aabb, &node.aabb)
+	if node.isLeaf() {
+		return aabbArea(&combined)
+	}
+	return aabbArea(&combined) - aabbArea(&node.aabb)
+}
+
+// removeLeaf removes the leaf from the tree, its sibling takes the place of
+// their parent.
+func (t *DynamicTree) removeLeaf(leaf int) {
+	if leaf == t.root {
+		t.root = nullNode
+		return
+	}
+
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	t.replaceChild(grandParent, parent, sibling)
+	t.nodes[sibling].parent = grandParent
+	t.release(parent)
+	t.refit(grandParent)
+}
+
+// replaceChild makes child the child of parent instead of old. If parent is
+// null child becomes the root.
+func (t *DynamicTree) replaceChild(parent, old, child int) {
+	if parent == nullNode {
+		t.root = child
+		return
+	}
+	if t.nodes[parent].left == old {
+		t.nodes[parent].left = child
+	} else {
+		t.nodes[parent].right = child
+	}
+}
+
+// refit walks from index to the root, balancing the nodes and recomputing
+// their height and AABB.
+func (t *DynamicTree) refit(index int) {
+	for index != nullNode {
+		index = t.balance(index)
+
+		node := &t.nodes[index]
+		left, right := &t.nodes[node.left], &t.nodes[node.right]
+		node.height = 1 + maxInt(left.height, right.height)
+		node.aabb = aabbUnion(&left.aabb, &right.aabb)
+
+		index = node.parent
+	}
+}
+
+// balance performs a left or right rotation if the node is imbalanced and
+// returns the index of the node that took its place.
+func (t *DynamicTree) balance(iA int) int {
+	A := &t.nodes[iA]
+	if A.isLeaf() || A.height < 2 {
+		return iA
+	}
+
+	iB, iC := A.left, A.right
+	B, C := &t.nodes[iB], &t.nodes[iC]
+	switch balance := C.height - B.height; {
+	case balance > 1:
+		// Rotate C up.
+		iF, iG := C.left, C.right
+		F, G := &t.nodes[iF], &t.nodes[iG]
+
+		C.left = iA
+		C.parent = A.parent
+		A.parent = iC
+		t.replaceChild(C.parent, iA, iC)
+
+		if F.height > G.height {
+			C.right, A.right, G.parent = iF, iG, iA
+			A.aabb = aabbUnion(&B.aabb, &G.aabb)
+			C.aabb = aabbUnion(&A.aabb, &F.aabb)
+			A.height = 1 + maxInt(B.height, G.height)
+			C.height = 1 + maxInt(A.height, F.height)
+		} else {
+			C.right, A.right, F.parent = iG, iF, iA
+			A.aabb = aabbUnion(&B.aabb, &F.aabb)
+			C.aabb = aabbUnion(&A.aabb, &G.aabb)
+			A.height = 1 + maxInt(B.height, F.height)
+			C.height = 1 + maxInt(A.height, G.height)
+		}
+		return iC
+
+	case balance < -1:
+		// Rotate B up.
+		iD, iE := B.left, B.right
+		D, E := &t.nodes[iD], &t.nodes[iE]
+
+		B.left = iA
+		B.parent = A.parent
+		A.parent = iB
+		t.replaceChild(B.parent, iA, iB)
+
+		if D.height > E.height {
+			B.right, A.left, E.parent = iD, iE, iA
+			A.aabb = aabbUnion(&C.aabb, &E.aabb)
+			B.aabb = aabbUnion(&A.aabb, &D.aabb)
+			A.height = 1 + maxInt(C.height, E.height)
+			B.height = 1 + maxInt(A.height, D.height)
+		} else {
+			B.right, A.left, D.parent = iE, iD, iA
+			A.aabb = aabbUnion(&C.aabb, &D.aabb)
+			B.aabb = aabbUnion(&A.aabb, &E.aabb)
+			A.height = 1 + maxInt(C.height, D.height)
+			B.height = 1 + maxInt(A.height, E.height)
+		}
+		return iB
+	}
+	return iA
+}
+
+// aabbUnion returns the smallest AABB containing a and b.
+func aabbUnion(a, b *AABB) AABB {
+	var u AABB
+	for i := 0; i < 3; i++ {
+		min := math.Min(a.Center[i]-a.HalfExtend[i], b.Center[i]-b.HalfExtend[i])
+		max := math.Max(a.Center[i]+a.HalfExtend[i], b.Center[i]+b.HalfExtend[i])
+		u.Center[i] = (min + max) / 2
+		u.HalfExtend[i] = (max - min) / 2
+	}
+	return u
+}
+
+// aabbContains returns true if b is inside a.
+func aabbContains(a, b *AABB) bool {
+	for i := 0; i < 3; i++ {
+		if b.Center[i]-b.HalfExtend[i] < a.Center[i]-a.HalfExtend[i] ||
+			b.Center[i]+b.HalfExtend[i] > a.Center[i]+a.HalfExtend[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// aabbArea returns the surface area of the AABB.
+func aabbArea(a *AABB) float32 {
+	e := &a.HalfExtend
+	return 8 * (e[0]*e[1] + e[1]*e[2] + e[2]*e[0])
+}
+
+// maxInt returns the biggest of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,188 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkTree verifies the structure of the tree and returns the amount of
+// leaves under index.
+func checkTree(t *testing.T, tree *DynamicTree, index, parent int) int {
+	node := &tree.nodes[index]
+	if node.parent != parent {
+		t.Fatalf("node %d parent = %d, want %d", index, node.parent, parent)
+	}
+	if node.isLeaf() {
+		if node.height != 0 {
+			t.Fatalf("leaf %d height = %d", index, node.height)
+		}
+		return 1
+	}
+
+	left, right := &tree.nodes[node.left], &tree.nodes[node.right]
+	if node.height != 1+maxInt(left.height, right.height) {
+		t.Fatalf("node %d height = %d, children %d and %d", index, node.height, left.height, right.height)
+	}
+	union := aabbUnion(&left.aabb, &right.aabb)
+	if !node.aabb.Center.EqualThreshold(&union.Center, 1e-4) || !node.aabb.HalfExtend.EqualThreshold(&union.HalfExtend, 1e-4) {
+		t.Fatalf("node %d aabb = %v, want %v", index, node.aabb, union)
+	}
+	return checkTree(t, tree, node.left, index) + checkTree(t, tree, node.right, index)
+}
+
+func randomAABB(r *rand.Rand) AABB {
+	return AABB{
+		Center:     glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50},
+		HalfExtend: glm.Vec3{r.Float32()*3 + 0.1, r.Float32()*3 + 0.1, r.Float32()*3 + 0.1},
+	}
+}
+
+func TestDynamicTree(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	tree := NewDynamicTree(0.5)
+
+	aabbs := make(map[int]AABB)
+	for n := 0; n < 1000; n++ {
+		aabb := randomAABB(r)
+		id := tree.Insert(&aabb, n)
+		aabbs[id] = aabb
+		if tree.Data(id) != n {
+			t.Fatalf("Data(%d) = %v, want %d", id, tree.Data(id), n)
+		}
+	}
+	for n := 0; n < 3000; n++ {
+		id := r.Intn(len(tree.nodes))
+		if _, ok := aabbs[id]; !ok {
+			continue
+		}
+		switch r.Intn(3) {
+		case 0:
+			tree.Remove(id)
+			delete(aabbs, id)
+		case 1:
+			aabb := aabbs[id]
+			aabb.Center.AddWith(&glm.Vec3{r.Float32() - 0.5, r.Float32() - 0.5, r.Float32() - 0.5})
+			tree.Move(id, &aabb)
+			aabbs[id] = aabb
+		case 2:
+			aabb := randomAABB(r)
+			if !tree.Move(id, &aabb) {
+				t.Errorf("Move(%d) didn't reinsert the proxy", id)
+			}
+			aabbs[id] = aabb
+		}
+	}
+
+	if leaves := checkTree(t, tree, tree.root, nullNode); leaves != len(aabbs) {
+		t.Fatalf("tree has %d leaves, want %d", leaves, len(aabbs))
+	}
+	if h := tree.Height(); h > 20 {
+		t.Errorf("height = %d for %d proxies", h, len(aabbs))
+	}
+	for id, aabb := range aabbs {
+		if fat := tree.FatAABB(id); !aabbContains(&fat, &aabb) {
+			t.Errorf("fat AABB %v of %d doesn't contain %v", fat, id, aabb)
+		}
+	}
+
+	// Compare the queries with brute force.
+	for n := 0; n < 50; n++ {
+		query := randomAABB(r)
+		query.HalfExtend.MulWith(3)
+		var got, want []int
+		tree.QueryAABB(&query, func(id int) bool {
+			got = append(got, id)
+			return true
+		})
+		for id := range aabbs {
+			if fat := tree.FatAABB(id); TestAABBAABB(&fat, &query) {
+				want = append(want, id)
+			}
+		}
+		sort.Ints(got)
+		sort.Ints(want)
+		if !equalInts(got, want) {
+			t.Errorf("[%d] QueryAABB = %v, want %v", n, got, want)
+		}
+	}
+
+	pairs := make(map[[2]int]bool)
+	tree.QueryPairs(func(a, b int) {
+		if a >= b || pairs[[2]int{a, b}] {
+			t.Errorf("pair {%d %d} reported twice or out of order", a, b)
+		}
+		pairs[[2]int{a, b}] = true
+	})
+	var want int
+	for a := range aabbs {
+		for b := range aabbs {
+			fa, fb := tree.FatAABB(a), tree.FatAABB(b)
+			if a < b && TestAABBAABB(&fa, &fb) {
+				want++
+				if !pairs[[2]int{a, b}] {
+					t.Errorf("pair {%d %d} missing", a, b)
+				}
+			}
+		}
+	}
+	if len(pairs) != want {
+		t.Errorf("QueryPairs reported %d pairs, want %d", len(pairs), want)
+	}
+
+	// The closest hit of a ray clipped by the callback is the closest hit of
+	// all the fat AABB.
+	for n := 0; n < 50; n++ {
+		ray := Ray{
+			Origin:    glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50},
+			Direction: glm.Vec3{r.Float32() - 0.5, r.Float32() - 0.5, r.Float32() - 0.5},
+		}
+		const maxT = 200
+		closest, got := -1, float32(maxT)
+		tree.QueryRay(&ray, maxT, func(id int, maxT float32) float32 {
+			fat := tree.FatAABB(id)
+			if h, ok := RaycastAABB(&ray, &fat, maxT); ok {
+				closest, got = id, h.T
+				return h.T
+			}
+			return maxT
+		})
+		wantID, wantT := -1, float32(maxT)
+		for id := range aabbs {
+			fat := tree.FatAABB(id)
+			if h, ok := RaycastAABB(&ray, &fat, wantT); ok {
+				wantID, wantT = id, h.T
+			}
+		}
+		if (wantID == -1) != (closest == -1) || got != wantT {
+			t.Errorf("[%d] QueryRay closest = %d at %f, want %d at %f", n, closest, got, wantID, wantT)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func BenchmarkDynamicTree_QueryPairs(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewDynamicTree(0.5)
+	for n := 0; n < 1000; n++ {
+		aabb := randomAABB(r)
+		tree.Insert(&aabb, nil)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.QueryPairs(func(a, b int) {})
+	}
+}
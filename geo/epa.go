@@ -0,0 +1,279 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+const (
+	// epaMaxIterations is the maximum amount of points EPA will add to its
+	// polytope before returning the best face found so far. Curved shapes
+	// need a lot of them when they overlap deeply.
+	epaMaxIterations = 255
+
+	// epaEpsilon is the relative tolerance used to decide EPA has converged.
+	epaEpsilon = 0.0001
+
+	// epaCoplanarEpsilon is the relative distance under which a point is
+	// considered to lie on the plane of a face of the polytope. It must be
+	// smaller than epaEpsilon.
+	epaCoplanarEpsilon = 0.00001
+)
+
+// epaFace is a triangle of the polytope built by EPA. The vertices are ordered
+// CCW when looking at the face from outside the polytope.
+type epaFace struct {
+	vertices [3]int
+	normal   glm.Vec3
+	distance float32
+}
+
+// epaEdge is an edge of the horizon seen from a new point of the polytope.
+type epaEdge struct {
+	a, b int
+}
+
+// epaPolytope is the convex polytope built on the Minkowski difference of 2
+// shapes.
+type epaPolytope struct {
+	points, a, b []glm.Vec3
+	faces        []epaFace
+}
+
+// EPA computes the penetration of the intersecting convex shapes a and b using
+// the Expanding Polytope Algorithm. s must be the simplex GJKSimplex terminated
+// with when it reported an intersection. It returns the penetration depth, the
+// contact normal pointing from a toward b, and the points of a and b the
+// deepest inside the other shape. Moving b by depth*normal separates the
+// shapes. It returns false if the Minkowski difference of the shapes is flat.
+// s isn't modified, EPA grows a copy of it.
+func EPA(a, b Support, s *Simplex) (depth float32, normal, pointA, pointB glm.Vec3, ok bool) {
+	tetrahedron := *s
+	if !epaTetrahedron(a, b, &tetrahedron) {
+		return
+	}
+
+	var p epaPolytope
+	for n := 0; n < 4; n++ {
+		p.addPoint(&tetrahedron.A[n], &tetrahedron.B[n])
+	}
+	// Orient the tetrahedron so its faces point outward.
+	if glm.ScalarTripleProduct(p.edge(0, 1), p.edge(0, 2), p.edge(0, 3)) > 0 {
+		p.points[1], p.points[2] = p.points[2], p.points[1]
+		p.a[1], p.a[2] = p.a[2], p.a[1]
+		p.b[1], p.b[2] = p.b[2], p.b[1]
+	}
+	p.addFace(0, 1, 2)
+	p.addFace(0, 3, 1)
+	p.addFace(0, 2, 3)
+	p.addFace(1, 3, 2)
+
+	for i := 0; i < epaMaxIterations; i++ {
+		face := &p.faces[p.closestFace()]
+
+		// Stop when the support point doesn't push the face further out.
+		sa, sb := a.Support(face.normal), b.Support(face.normal.Inverse())
+		w := sa.Sub(&sb)
+		if d := w.Dot(&face.normal); d-face.distance <= epaEpsilon*math.Max(1, face.distance) {
+			break
+		}
+		if !p.expand(&sa, &sb, face.distance) {
+			break
+		}
+	}
+
+	face := &p.faces[p.closestFace()]
+	depth, normal = face.distance, face.normal
+	pointA, pointB = p.witnesses(face)
+	ok = true
+	return
+}
+
+// epaTetrahedron grows s to a tetrahedron with a non-zero volume by adding
+// support points of the Minkowski difference of a and b. It returns false if
+// that is impossible.
+func epaTetrahedron(a, b Support, s *Simplex) bool {
+	axes := [...]glm.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	// merge adds the support point along direction if it grows the simplex.
+	merge := func(direction glm.Vec3, minLen2 float32) bool {
+		for _, d := range [2]glm.Vec3{direction, direction.Inverse()} {
+			sa, sb := a.Support(d), b.Support(d.Inverse())
+			w := sa.Sub(&sb)
+			dist := w.Sub(&s.Points[0])
+			if s.Size == 2 {
+				ab := s.Points[1].Sub(&s.Points[0])
+				dist = ab.Cross(&dist)
+			}
+			if dist.Len2() > minLen2 {
+				s.MergeSupport(&sa, &sb)
+				return true
+			}
+		}
+		return false
+	}
+
+	tolerance := gjkEpsilon * gjkEpsilon * math.Max(1, s.maxLen2())
+	if s.Size == 1 {
+		for n := 0; n < len(axes) && s.Size == 1; n++ {
+			merge(axes[n], tolerance)
+		}
+	}
+	if s.Size == 2 {
+		// Search directions perpendicular to the segment.
+		ab := s.Points[1].Sub(&s.Points[0])
+		for n := 0; n < len(axes) && s.Size == 2; n++ {
+			d := ab.Cross(&axes[n])
+			if d.Len2() > tolerance {
+				merge(d, tolerance*ab.Len2())
+			}
+		}
+	}
+	if s.Size == 3 {
+		ab, ac := s.Points[1].Sub(&s.Points[0]), s.Points[2].Sub(&s.Points[0])
+		n := ab.Cross(&ac)
+		nn := n.Len2()
+		if nn > 0 {
+			for _, d := range [2]glm.Vec3{n, n.Inverse()} {
+				sa, sb := a.Support(d), b.Support(d.Inverse())
+				w := sa.Sub(&sb)
+				aw := w.Sub(&s.Points[0])
+				if dist := aw.Dot(&n); dist*dist > tolerance*nn {
+					s.MergeSupport(&sa, &sb)
+					break
+				}
+			}
+		}
+	}
+	return s.Size == 4
+}
+
+// addPoint adds the point sa-sb to the polytope and returns its index.
+func (p *epaPolytope) addPoint(sa, sb *glm.Vec3) int {
+	p.points = append(p.points, sa.Sub(sb))
+	p.a = append(p.a, *sa)
+	p.b = append(p.b, *sb)
+	return len(p.points) - 1
+}
+
+// edge returns the vector from point i to point j.
+func (p *epaPolytope) edge(i, j int) *glm.Vec3 {
+	e := p.points[j].Sub(&p.points[i])
+	return &e
+}
+
+// addFace adds the face {i j k}, CCW seen from outside, to the polytope.
+func (p *epaPolytope) addFace(i, j, k int) {
+	p.faces = append(p.faces, p.newFace(i, j, k))
+}
+
+// newFace returns the face {i j k}, CCW seen from outside.
+func (p *epaPolytope) newFace(i, j, k int) epaFace {
+	n := p.edge(i, j).Cross(p.edge(i, k))
+	n.Normalize()
+	return epaFace{
+		vertices: [3]int{i, j, k},
+		normal:   n,
+		distance: n.Dot(&p.points[i]),
+	}
+}
+
+// closestFace returns the index of the face closest to origin.
+func (p *epaPolytope) closestFace() int {
+	var closest int
+	for n := range p.faces {
+		if p.faces[n].distance < p.faces[closest].distance {
+			closest = n
+		}
+	}
+	return closest
+}
+
+// expand adds sa-sb to the polytope, removing every face it can see and
+// stitching the horizon to it. minDistance is the distance from origin to the
+// closest face. It returns false and leaves the polytope untouched if the new
+// point doesn't see any face or if numerical errors would make the polytope
+// invalid.
+func (p *epaPolytope) expand(sa, sb *glm.Vec3, minDistance float32) bool {
+	w := sa.Sub(sb)
+
+	// The edges of the visible faces that are only seen once form the
+	// horizon. Faces coplanar with w are considered visible so w is never
+	// aligned with a horizon edge.
+	tolerance := epaCoplanarEpsilon * math.Max(1, w.Len())
+	visible := make([]bool, len(p.faces))
+	var horizon []epaEdge
+	for n, face := range p.faces {
+		vw := w.Sub(&p.points[face.vertices[0]])
+		if vw.Dot(&face.normal) < -tolerance {
+			continue
+		}
+		visible[n] = true
+		for m := 0; m < 3; m++ {
+			e := epaEdge{face.vertices[m], face.vertices[(m+1)%3]}
+			found := false
+			for k := range horizon {
+				if horizon[k].a == e.b && horizon[k].b == e.a {
+					horizon = append(horizon[:k], horizon[k+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				horizon = append(horizon, e)
+			}
+		}
+	}
+	if len(horizon) == 0 {
+		return false
+	}
+
+	// The polytope only grows, so every new face must be at least as far from
+	// origin as the closest face. If it isn't, or if the face is degenerate and
+	// its distance is NaN, the visible faces didn't form a single patch.
+	i := p.addPoint(sa, sb)
+	newfaces := make([]epaFace, len(horizon))
+	for n, e := range horizon {
+		newfaces[n] = p.newFace(e.a, e.b, i)
+		if !(newfaces[n].distance >= minDistance-tolerance) {
+			p.points, p.a, p.b = p.points[:i], p.a[:i], p.b[:i]
+			return false
+		}
+	}
+
+	var kept int
+	for n, face := range p.faces {
+		if !visible[n] {
+			p.faces[kept] = face
+			kept++
+		}
+	}
+	p.faces = append(p.faces[:kept], newfaces...)
+	return true
+}
+
+// witnesses returns the points of the 2 shapes whose difference is the
+// projection of origin on face.
+func (p *epaPolytope) witnesses(face *epaFace) (a, b glm.Vec3) {
+	i, j, k := face.vertices[0], face.vertices[1], face.vertices[2]
+	origin := face.normal.Mul(face.distance)
+
+	// The barycentric coordinates are the areas of the sub-triangles formed
+	// with the projection of origin.
+	var weights [3]float32
+	for n, e := range [3][2]int{{j, k}, {k, i}, {i, j}} {
+		u, v := p.points[e[0]].Sub(&origin), p.points[e[1]].Sub(&origin)
+		c := u.Cross(&v)
+		weights[n] = c.Dot(&face.normal)
+	}
+	sum := weights[0] + weights[1] + weights[2]
+	if sum == 0 {
+		return p.a[i], p.b[i]
+	}
+
+	for n, v := range [3]int{i, j, k} {
+		a.AddScaledVec(weights[n]/sum, &p.a[v])
+		b.AddScaledVec(weights[n]/sum, &p.b[v])
+	}
+	return
+}
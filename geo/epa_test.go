@@ -0,0 +1,80 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestEPA(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b   Support
+		depth  float32
+		normal glm.Vec3
+	}{
+		{ // 0
+			a:      &Sphere{Center: glm.Vec3{0, 0, 0}, Radius: 1},
+			b:      &Sphere{Center: glm.Vec3{1.5, 0, 0}, Radius: 1},
+			depth:  0.5,
+			normal: glm.Vec3{1, 0, 0},
+		},
+		{ // 1
+			a:      &AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}},
+			b:      &AABB{Center: glm.Vec3{0.5, 1.8, 0.2}, HalfExtend: glm.Vec3{1, 1, 1}},
+			depth:  0.2,
+			normal: glm.Vec3{0, 1, 0},
+		},
+		{ // 2
+			a:      boxPoints(glm.Vec3{0, 0, 0}, glm.Vec3{2, 2, 2}),
+			b:      &Sphere{Center: glm.Vec3{0, 0, -2.5}, Radius: 1},
+			depth:  0.5,
+			normal: glm.Vec3{0, 0, -1},
+		},
+		{ // 3 GJK terminates with a segment
+			a:      &AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}},
+			b:      &AABB{Center: glm.Vec3{1.5, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}},
+			depth:  0.5,
+			normal: glm.Vec3{1, 0, 0},
+		},
+		{ // 4 same shape
+			a:     &AABB{Center: glm.Vec3{3, 2, 1}, HalfExtend: glm.Vec3{1, 1, 1}},
+			b:     &AABB{Center: glm.Vec3{3, 2, 1}, HalfExtend: glm.Vec3{1, 1, 1}},
+			depth: 2,
+		},
+		{ // 5
+			a:      &Capsule{A: glm.Vec3{-3, 0, 0}, B: glm.Vec3{3, 0, 0}, Radius: 1},
+			b:      &Sphere{Center: glm.Vec3{1, -1.5, 0}, Radius: 1},
+			depth:  0.5,
+			normal: glm.Vec3{0, -1, 0},
+		},
+	}
+
+	for i, test := range tests {
+		var s Simplex
+		if _, intersecting := GJKSimplex(test.a, test.b, &s); !intersecting {
+			t.Errorf("[%d] GJK reported no intersection", i)
+			continue
+		}
+		simplex := s
+		depth, normal, pointA, pointB, ok := EPA(test.a, test.b, &s)
+		if s != simplex {
+			t.Errorf("[%d] EPA modified the simplex", i)
+		}
+		if !ok {
+			t.Errorf("[%d] EPA failed", i)
+			continue
+		}
+		// The normal converges slower than the depth on curved shapes.
+		if math.Abs(depth-test.depth) > 1e-3 {
+			t.Errorf("[%d] depth = %f, want %f", i, depth, test.depth)
+		}
+		if d := normal.Sub(&test.normal); test.normal != (glm.Vec3{}) && d.Len() > 2e-2 {
+			t.Errorf("[%d] normal = %v, want %v", i, normal, test.normal)
+		}
+		diff, want := pointA.Sub(&pointB), normal.Mul(depth)
+		if d := diff.Sub(&want); d.Len() > 1e-3 {
+			t.Errorf("[%d] pointA - pointB = %v, want %v", i, diff, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"strconv"
+)
+
+// Classification is the result of testing a bounding volume against a
+// Frustum.
+type Classification int
+
+// The classifications of a bounding volume against a Frustum.
+const (
+	Outside Classification = iota
+	Intersecting
+	Inside
+)
+
+// String returns the name of the classification.
+func (c Classification) String() string {
+	switch c {
+	case Outside:
+		return "Outside"
+	case Intersecting:
+		return "Intersecting"
+	case Inside:
+		return "Inside"
+	}
+	return "Classification(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Frustum is the volume seen by a camera, the intersection of the half-spaces
+// in front of its planes.
+type Frustum struct {
+	// Planes are the left, right, bottom, top, near and far planes, in that
+	// order. Their normal is normalized and points inside the frustum. A plane
+	// at infinity, like the far plane of an infinite projection, has a zero
+	// normal and is ignored.
+	Planes [6]Plane
+}
+
+// FrustumFromMatrix extracts the frustum of the view-projection matrix m,
+// usually projection*view with a projection from glm.Perspective, glm.Ortho
+// or glm.Frustum. The frustum is in the space m transforms from, world space
+// for a view-projection matrix. m must map the frustum to the clip space cube
+// -w <= x, y, z <= w.
+func FrustumFromMatrix(m *glm.Mat4) Frustum {
+	return FrustumFromMatrixDepth(m, glm.DepthNegativeOneToOne)
+}
+
+// FrustumFromMatrixDepth is like FrustumFromMatrix for a projection using the
+// depth range depth.
+func FrustumFromMatrixDepth(m *glm.Mat4, depth glm.DepthRange) Frustum {
+	row0, row1, row2, row3 := m.Rows()
+	planes := [6]glm.Vec4{
+		row3.Add(&row0), row3.Sub(&row0),
+		row3.Add(&row1), row3.Sub(&row1),
+		row3.Add(&row2), row3.Sub(&row2),
+	}
+	switch depth {
+	case glm.DepthZeroToOne:
+		planes[4] = row2
+	case glm.DepthReverseZ:
+		planes[4], planes[5] = planes[5], row2
+	}
+
+	var f Frustum
+	for n := range planes {
+		f.Planes[n] = planeFromVec4(&planes[n])
+	}
+	return f
+}
+
+// planeFromVec4 returns the plane of the points p for which
+// v[0]*p[0] + v[1]*p[1] + v[2]*p[2] + v[3] = 0, its normal normalized.
+func planeFromVec4(v *glm.Vec4) Plane {
+	n := glm.Vec3{v[0], v[1], v[2]}
+	l := n.Len()
+	if l == 0 {
+		return Plane{}
+	}
+	n.MulWith(1 / l)
+	return Plane{N: n, P: n.Mul(-v[3] / l)}
+}
+
+// FrustumCorners returns the 8 corners of the frustum of the view-projection
+// matrix m, see FrustumFromMatrix. Corner n is on the right if n&1 != 0, on
+// the top if n&2 != 0 and on the far plane if n&4 != 0. The corners are not
+// finite if the far plane is at infinity.
+func FrustumCorners(m *glm.Mat4) [8]glm.Vec3 {
+	return FrustumCornersDepth(m, glm.DepthNegativeOneToOne)
+}
+
+// FrustumCornersDepth is like FrustumCorners for a projection using the depth
+// range depth.
+func FrustumCornersDepth(m *glm.Mat4, depth glm.DepthRange) [8]glm.Vec3 {
+	near, far := depth.NDC()
+
+	inv := m.Inverse()
+	var corners [8]glm.Vec3
+	for n := range corners {
+		ndc := glm.Vec4{-1, -1, near, 1}
+		for i := 0; i < 2; i++ {
+			if n&(1<<uint(i)) != 0 {
+				ndc[i] = 1
+			}
+		}
+		if n&4 != 0 {
+			ndc[2] = far
+		}
+		p := inv.Mul4x1(&ndc)
+		corners[n] = glm.Vec3{p[0] / p[3], p[1] / p[3], p[2] / p[3]}
+	}
+	return corners
+}
+
+// ClassifySphere returns whether the sphere is inside, outside or intersecting
+// the frustum. Spheres near the corners of the frustum might be reported as
+// intersecting while they are outside.
+func (f *Frustum) ClassifySphere(s *Sphere) Classification {
+	return f.classify(&s.Center, func(n *glm.Vec3) float32 {
+		return s.Radius
+	})
+}
+
+// ClassifyAABB returns whether the AABB is inside, outside or intersecting the
+// frustum. AABB near the corners of the frustum might be reported as
+// intersecting while they are outside.
+func (f *Frustum) ClassifyAABB(a *AABB) Classification {
+	return f.classify(&a.Center, func(n *glm.Vec3) float32 {
+		return a.HalfExtend[0]*math.Abs(n[0]) + a.HalfExtend[1]*math.Abs(n[1]) + a.HalfExtend[2]*math.Abs(n[2])
+	})
+}
+
+// ClassifyOBB returns whether the OBB is inside, outside or intersecting the
+// frustum. OBB near the corners of the frustum might be reported as
+// intersecting while they are outside.
+func (f *Frustum) ClassifyOBB(o *OBB) Classification {
+	return f.classify(&o.Center, func(n *glm.Vec3) float32 {
+		return o.HalfExtend[0]*math.Abs(n.Dot(&o.Orientation[0])) +
+			o.HalfExtend[1]*math.Abs(n.Dot(&o.Orientation[1])) +
+			o.HalfExtend[2]*math.Abs(n.Dot(&o.Orientation[2]))
+	})
+}
+
+// TestSphere returns true if the sphere isn't outside the frustum.
+func (f *Frustum) TestSphere(s *Sphere) bool {
+	return f.ClassifySphere(s) != Outside
+}
+
+// TestAABB returns true if the AABB isn't outside the frustum.
+func (f *Frustum) TestAABB(a *AABB) bool {
+	return f.ClassifyAABB(a) != Outside
+}
+
+// classify classifies a volume symmetric around center whose projection on the
+// normal n of a plane has the radius returned by radius.
+func (f *Frustum) classify(center *glm.Vec3, radius func(n *glm.Vec3) float32) Classification {
+	c := Inside
+	for n := range f.Planes {
+		p := &f.Planes[n]
+		if p.N == (glm.Vec3{}) {
+			continue
+		}
+		d, r := DistanceToPlane(p, center), radius(&p.N)
+		if d < -r {
+			return Outside
+		}
+		if d < r {
+			c = Intersecting
+		}
+	}
+	return c
+}
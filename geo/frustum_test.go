@@ -0,0 +1,170 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestFrustumFromMatrix(t *testing.T) {
+	t.Parallel()
+	// A camera at (0, 0, 10) looking at -z with a 90 degrees field of view.
+	proj := glm.Perspective(math.Pi/2, 1, 1, 100)
+	view := glm.LookAtV(&glm.Vec3{0, 0, 10}, &glm.Vec3{0, 0, 0}, &glm.Vec3{0, 1, 0})
+	m := proj.Mul4(&view)
+	f := FrustumFromMatrix(&m)
+
+	s2 := math.Sqrt(2) / 2
+	normals := [6]glm.Vec3{{s2, 0, -s2}, {-s2, 0, -s2}, {0, s2, -s2}, {0, -s2, -s2}, {0, 0, -1}, {0, 0, 1}}
+	for n, want := range normals {
+		if !f.Planes[n].N.EqualThreshold(&want, 1e-4) {
+			t.Errorf("[%d] normal = %v, want %v", n, f.Planes[n].N, want)
+		}
+	}
+	if d := DistanceToPlane(&f.Planes[4], &glm.Vec3{0, 0, 9}); math.Abs(d) > 1e-4 {
+		t.Errorf("near plane distance to (0, 0, 9) = %f, want 0", d)
+	}
+	if d := DistanceToPlane(&f.Planes[5], &glm.Vec3{0, 0, -90}); math.Abs(d) > 1e-3 {
+		t.Errorf("far plane distance to (0, 0, -90) = %f, want 0", d)
+	}
+
+	corners := FrustumCorners(&m)
+	want := [8]glm.Vec3{
+		{-1, -1, 9}, {1, -1, 9}, {-1, 1, 9}, {1, 1, 9},
+		{-100, -100, -90}, {100, -100, -90}, {-100, 100, -90}, {100, 100, -90},
+	}
+	for n := range corners {
+		if d := corners[n].Sub(&want[n]); d.Len() > 1e-2 {
+			t.Errorf("[%d] corner = %v, want %v", n, corners[n], want[n])
+		}
+		// Every corner is on 3 planes and in front of the others.
+		var on int
+		for k := range f.Planes {
+			d := DistanceToPlane(&f.Planes[k], &corners[n])
+			if math.Abs(d) < 1e-2 {
+				on++
+			} else if d < 0 {
+				t.Errorf("[%d] corner behind plane %d", n, k)
+			}
+		}
+		if on != 3 {
+			t.Errorf("[%d] corner on %d planes, want 3", n, on)
+		}
+	}
+}
+
+func TestFrustum_Classify(t *testing.T) {
+	t.Parallel()
+	proj := glm.Ortho(-10, 10, -10, 10, 0, 20)
+	view := glm.LookAtV(&glm.Vec3{0, 0, 10}, &glm.Vec3{0, 0, 0}, &glm.Vec3{0, 1, 0})
+	m := proj.Mul4(&view)
+	// The frustum is the box [-10, 10]^3.
+	f := FrustumFromMatrix(&m)
+
+	tests := []struct {
+		center glm.Vec3
+		size   float32
+		sphere Classification
+		aabb   Classification
+	}{
+		{glm.Vec3{0, 0, 0}, 1, Inside, Inside},
+		{glm.Vec3{9, 0, 0}, 0.5, Inside, Inside},
+		{glm.Vec3{9.5, 0, 0}, 1, Intersecting, Intersecting},
+		{glm.Vec3{0, 0, -11}, 2, Intersecting, Intersecting},
+		{glm.Vec3{0, 12, 0}, 1, Outside, Outside},
+		{glm.Vec3{0, 0, 12}, 1, Outside, Outside},
+		{glm.Vec3{0, 0, 0}, 50, Intersecting, Intersecting},
+		// The sphere is outside, near an edge, but the planes can't tell.
+		{glm.Vec3{11.2, 11.2, 0}, 1.5, Intersecting, Intersecting},
+		{glm.Vec3{11.5, 0, 0}, 1.4, Outside, Outside},
+	}
+
+	for i, test := range tests {
+		s := Sphere{Center: test.center, Radius: test.size}
+		if c := f.ClassifySphere(&s); c != test.sphere {
+			t.Errorf("[%d] ClassifySphere = %s, want %s", i, c, test.sphere)
+		}
+		a := AABB{Center: test.center, HalfExtend: glm.Vec3{test.size, test.size, test.size}}
+		if c := f.ClassifyAABB(&a); c != test.aabb {
+			t.Errorf("[%d] ClassifyAABB = %s, want %s", i, c, test.aabb)
+		}
+		if f.TestAABB(&a) != (test.aabb != Outside) {
+			t.Errorf("[%d] TestAABB = %t", i, f.TestAABB(&a))
+		}
+
+		// An OBB rotated by 90 degrees is the same as the AABB.
+		o := OBB{Center: test.center, HalfExtend: a.HalfExtend, Orientation: [3]glm.Vec3{{0, 1, 0}, {0, 0, 1}, {1, 0, 0}}}
+		if c := f.ClassifyOBB(&o); c != test.aabb {
+			t.Errorf("[%d] ClassifyOBB = %s, want %s", i, c, test.aabb)
+		}
+	}
+
+	// An OBB rotated by 45 degrees around z.
+	s2 := math.Sqrt(2) / 2
+	o := OBB{Center: glm.Vec3{11.2, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}, Orientation: [3]glm.Vec3{{s2, s2, 0}, {-s2, s2, 0}, {0, 0, 1}}}
+	if c := f.ClassifyOBB(&o); c != Intersecting {
+		t.Errorf("ClassifyOBB = %s, want %s", c, Intersecting)
+	}
+	o.Center[0] = 11.5
+	if c := f.ClassifyOBB(&o); c != Outside {
+		t.Errorf("ClassifyOBB = %s, want %s", c, Outside)
+	}
+}
+
+func TestFrustum_InfiniteFar(t *testing.T) {
+	t.Parallel()
+	// The far plane of an infinite perspective, (0 0 0 2*near), is ignored.
+	m := glm.Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, -1, -1,
+		0, 0, -2, 0,
+	}
+	f := FrustumFromMatrix(&m)
+	if f.Planes[5].N != (glm.Vec3{}) {
+		t.Errorf("far plane = %v, want zero", f.Planes[5])
+	}
+	s := Sphere{Center: glm.Vec3{0, 0, -1e6}, Radius: 1}
+	if c := f.ClassifySphere(&s); c != Inside {
+		t.Errorf("ClassifySphere = %s, want %s", c, Inside)
+	}
+}
+
+func TestFrustumFromMatrixDepth(t *testing.T) {
+	t.Parallel()
+	view := glm.LookAtV(&glm.Vec3{1, 2, 10}, &glm.Vec3{0, 0, 0}, &glm.Vec3{0, 1, 0})
+	proj := glm.PerspectiveDepth(math.Pi/3, 1.5, 1, 100, glm.DepthNegativeOneToOne)
+	m := proj.Mul4(&view)
+	want, wantCorners := FrustumFromMatrix(&m), FrustumCorners(&m)
+
+	// Every depth range describes the same frustum.
+	for i, depth := range []glm.DepthRange{glm.DepthZeroToOne, glm.DepthReverseZ} {
+		proj := glm.PerspectiveDepth(math.Pi/3, 1.5, 1, 100, depth)
+		m := proj.Mul4(&view)
+		f, corners := FrustumFromMatrixDepth(&m, depth), FrustumCornersDepth(&m, depth)
+		for n := range f.Planes {
+			if !f.Planes[n].N.EqualThreshold(&want.Planes[n].N, 1e-4) {
+				t.Errorf("[%d] plane %d normal = %v, want %v", i, n, f.Planes[n].N, want.Planes[n].N)
+			}
+			if d := DistanceToPlane(&f.Planes[n], &want.Planes[n].P); math.Abs(d) > 1e-2 {
+				t.Errorf("[%d] plane %d is %f away from %v", i, n, d, want.Planes[n])
+			}
+		}
+		for n := range corners {
+			if d := corners[n].Sub(&wantCorners[n]); d.Len() > 1e-2 {
+				t.Errorf("[%d] corner %d = %v, want %v", i, n, corners[n], wantCorners[n])
+			}
+		}
+	}
+
+	// The far plane of an infinite reverse-Z projection is ignored.
+	proj = glm.PerspectiveInfinite(math.Pi/3, 1.5, 1, glm.DepthReverseZ)
+	m = proj.Mul4(&view)
+	f := FrustumFromMatrixDepth(&m, glm.DepthReverseZ)
+	if f.Planes[5].N != (glm.Vec3{}) {
+		t.Errorf("far plane = %v, want zero", f.Planes[5])
+	}
+	if !f.Planes[4].N.EqualThreshold(&want.Planes[4].N, 1e-4) {
+		t.Errorf("near plane normal = %v, want %v", f.Planes[4].N, want.Planes[4].N)
+	}
+}
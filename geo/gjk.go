@@ -0,0 +1,68 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+const (
+	// gjkMaxIterations is the maximum amount of points GJK will add to its
+	// simplex before giving up, it is only reached in degenerate cases.
+	gjkMaxIterations = 64
+
+	// gjkEpsilon is the relative tolerance used to decide GJK has converged.
+	// It is about 100 times the float32 precision, so rounding errors in the
+	// support points can't keep the algorithm from terminating.
+	gjkEpsilon = 0.00001
+)
+
+// GJK computes the distance between convex shapes a and b using the
+// Gilbert-Johnson-Keerthi algorithm. It returns the distance, the points of a
+// and b closest to each other and whether the shapes intersect. When they do
+// the distance is 0 and closestA == closestB is a point in both shapes.
+func GJK(a, b Support) (distance float32, closestA, closestB glm.Vec3, intersecting bool) {
+	var s Simplex
+	distance, intersecting = GJKSimplex(a, b, &s)
+	closestA, closestB = s.Witnesses()
+	return
+}
+
+// GJKSimplex is GJK but it also returns the simplex the algorithm terminated
+// with in s, it can be used as a starting point for EPA. It returns the distance
+// and whether the shapes intersect.
+func GJKSimplex(a, b Support, s *Simplex) (distance float32, intersecting bool) {
+	// Start with an arbitrary point of the Minkowski difference.
+	v := glm.Vec3{1, 0, 0}
+	s.Size = 0
+	sa, sb := a.Support(v), b.Support(v.Inverse())
+	s.MergeSupport(&sa, &sb)
+	s.Weights[0] = 1
+	v = s.Points[0]
+
+	for i := 0; i < gjkMaxIterations; i++ {
+		vv := v.Len2()
+
+		// Find the point of the Minkowski difference furthest toward origin.
+		dir := v.Inverse()
+		sa, sb = a.Support(dir), b.Support(v)
+		w := sa.Sub(&sb)
+
+		// If w isn't closer to origin than v, v is the closest point.
+		if vv-v.Dot(&w) <= gjkEpsilon*vv || s.contains(&w) {
+			break
+		}
+
+		s.MergeSupport(&sa, &sb)
+		direction, containsOrigin := s.NearestToOrigin()
+		if containsOrigin {
+			return 0, true
+		}
+
+		v = direction.Inverse()
+		if v.Len2() <= gjkEpsilon*gjkEpsilon*s.maxLen2() {
+			return 0, true
+		}
+	}
+
+	return math.Sqrt(v.Len2()), false
+}
@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+// supportPoints is the convex hull of a set of points.
+type supportPoints []glm.Vec3
+
+func (s supportPoints) Support(direction glm.Vec3) glm.Vec3 {
+	_, imax := ExtremePointsAlongDirection(&direction, s)
+	return s[imax]
+}
+
+// supportSphere is a sphere.
+type supportSphere struct {
+	center glm.Vec3
+	radius float32
+}
+
+func (s supportSphere) Support(direction glm.Vec3) glm.Vec3 {
+	direction.Normalize()
+	p := s.center
+	p.AddScaledVec(s.radius, &direction)
+	return p
+}
+
+// boxPoints returns the 8 corners of a box.
+func boxPoints(center, halfExtend glm.Vec3) supportPoints {
+	var points supportPoints
+	for n := 0; n < 8; n++ {
+		p := center
+		for i := uint(0); i < 3; i++ {
+			if n&(1<<i) == 0 {
+				p[i] -= halfExtend[i]
+			} else {
+				p[i] += halfExtend[i]
+			}
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+func TestGJK(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b               Support
+		distance           float32
+		closestA, closestB glm.Vec3
+		intersecting       bool
+	}{
+		{ // 0 separated spheres
+			a:        supportSphere{glm.Vec3{0, 0, 0}, 1},
+			b:        supportSphere{glm.Vec3{4, 0, 0}, 1},
+			distance: 2,
+			closestA: glm.Vec3{1, 0, 0},
+			closestB: glm.Vec3{3, 0, 0},
+		},
+		{ // 1 overlapping spheres
+			a:            supportSphere{glm.Vec3{0, 0, 0}, 1},
+			b:            supportSphere{glm.Vec3{1, 1, 0}, 1},
+			intersecting: true,
+		},
+		{ // 2 boxes face to face
+			a:        boxPoints(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}),
+			b:        boxPoints(glm.Vec3{0, 3, 0}, glm.Vec3{0.5, 0.5, 0.5}),
+			distance: 1.5,
+			closestA: glm.Vec3{0, 1, 0},
+			closestB: glm.Vec3{0, 2.5, 0},
+		},
+		{ // 3 boxes vertex to vertex
+			a:        boxPoints(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}),
+			b:        boxPoints(glm.Vec3{3, 3, 3}, glm.Vec3{1, 1, 1}),
+			distance: math.Sqrt(3),
+			closestA: glm.Vec3{1, 1, 1},
+			closestB: glm.Vec3{2, 2, 2},
+		},
+		{ // 4 box and sphere overlapping
+			a:            boxPoints(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}),
+			b:            supportSphere{glm.Vec3{1.5, 0, 0}, 1},
+			intersecting: true,
+		},
+		{ // 5 segment and box
+			a:        supportPoints{{-5, 2, 0}, {5, 2, 0}},
+			b:        boxPoints(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}),
+			distance: 1,
+			closestA: glm.Vec3{0, 2, 0},
+			closestB: glm.Vec3{0, 1, 0},
+		},
+	}
+
+	for i, test := range tests {
+		distance, closestA, closestB, intersecting := GJK(test.a, test.b)
+		if intersecting != test.intersecting || !glm.FloatEqualThreshold(distance, test.distance, 1e-4) {
+			t.Errorf("[%d] distance = %f, intersecting = %t, want %f, %t", i,
+				distance, intersecting, test.distance, test.intersecting)
+			continue
+		}
+		if intersecting {
+			continue
+		}
+		// When the closest features are parallel the witness points are not
+		// unique, only check the distance between them and their axis.
+		d := closestB.Sub(&closestA)
+		want := test.closestB.Sub(&test.closestA)
+		if !d.EqualThreshold(&want, 1e-3) || !glm.FloatEqualThreshold(d.Len(), distance, 1e-4) {
+			t.Errorf("[%d] closestB - closestA = %v, want %v", i, d, want)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package qhull
 
 import (
-	"fmt"
 	"github.com/engoengine/glm"
 	"github.com/EngoEngine/math"
 )
@@ -20,7 +19,6 @@ type (
 	// Face is a quickhull utility struct for faces
 	Face struct {
 		Edges     [3]*Edge
-		Faces     [3]*Face
 		Vertices  [3]int
 		Conflicts []Conflict
 
@@ -37,9 +35,36 @@ type (
 	}
 )
 
-func (f *Face) canSee(point *glm.Vec3) bool {
+// NewFace returns a face {a b c} with its 3 edges circularly linked. The
+// normal of the face is (c-a)x(b-a), normalized. The twins of the edges are
+// left nil.
+func NewFace(a, b, c int, points []glm.Vec3) *Face {
+	ab := points[b].Sub(&points[a])
+	ac := points[c].Sub(&points[a])
+
+	f := &Face{Vertices: [3]int{a, b, c}, Normal: ac.Cross(&ab), Point: points[a]}
+	f.Normal.Normalize()
+
+	e0 := &Edge{Tail: a, Face: f}
+	e1 := &Edge{Tail: b, Face: f}
+	e2 := &Edge{Tail: c, Face: f}
+
+	e0.Next, e0.Prev = e1, e2
+	e1.Next, e1.Prev = e2, e0
+	e2.Next, e2.Prev = e0, e1
+
+	f.Edges = [3]*Edge{e0, e1, e2}
+	return f
+}
+
+// Distance returns the signed distance of point to the plane of the face.
+func (f *Face) Distance(point *glm.Vec3) float32 {
 	ap := point.Sub(&f.Point)
-	return ap.Dot(&f.Normal) > 0
+	return ap.Dot(&f.Normal)
+}
+
+func (f *Face) canSee(point *glm.Vec3) bool {
+	return f.Distance(point) > 0
 }
 
 // CleanVisited clears the visited field of all the faces of the convex hull.
@@ -49,48 +74,34 @@ func CleanVisited(faces []*Face) {
 	}
 }
 
-// FindHorizon finds the horizon of the conflict
+// FindHorizon marks every face visible from point as visited and returns the
+// horizon, the edges of the visible faces that border a face that is not
+// visible. The edges are returned in order, the head of one edge is the tail of
+// the next one. face must be visible from point and every face must have been
+// cleaned of their visited flag.
 func FindHorizon(face *Face, point *glm.Vec3) []*Edge {
-	return findHorizon(face, nil, point)
+	var horizon []*Edge
+	face.Visited = true
+	for _, e := range face.Edges {
+		horizon = findHorizon(e, point, horizon)
+	}
+	return horizon
 }
 
-func findHorizon(face *Face, edge *Edge, point *glm.Vec3) []*Edge {
-	fmt.Println("face, edge", fmt.Sprintf("%p", face), fmt.Sprintf("%p", edge), " visited ", face.Visited)
-	if edge == nil {
-		e := face.Edges[0]
-		fmt.Println("nil 1")
-		edges := findHorizon(e.Twin.Face, e.Twin, point)
-		e = e.Next
-		fmt.Println("nil 2")
-		edges = append(edges, findHorizon(e.Twin.Face, e.Twin, point)...)
-		e = e.Next
-		fmt.Println("nil 3")
-		edges = append(edges, findHorizon(e.Twin.Face, e.Twin, point)...)
-		return edges
-	}
-	if face.Visited {
-		fmt.Println("visited")
-		return nil
+// findHorizon crosses edge e and keeps walking through visible faces.
+func findHorizon(e *Edge, point *glm.Vec3, horizon []*Edge) []*Edge {
+	neighbor := e.Twin.Face
+	if neighbor.Visited {
+		return horizon
 	}
 
-	if !face.canSee(point) {
-		fmt.Printf("add %p\n", edge.Twin)
-		return []*Edge{edge.Twin}
+	if !neighbor.canSee(point) {
+		return append(horizon, e)
 	}
 
-	fmt.Println("setting visited")
-	face.Visited = true
-
-	e := edge.Next
-	fmt.Print("non-nil 1 ")
-	edges := findHorizon(e.Twin.Face, e.Twin, point)
-	fmt.Println(edges)
-	e = e.Next
-	fmt.Print("non-nil 2 ")
-	edges = append(edges, findHorizon(e.Twin.Face, e.Twin, point)...)
-	fmt.Println(edges)
-
-	return edges
+	neighbor.Visited = true
+	horizon = findHorizon(e.Twin.Next, point, horizon)
+	return findHorizon(e.Twin.Prev, point, horizon)
 }
 
 // NextConflict returns the index of the face and conflict of the conflict with
@@ -116,9 +127,8 @@ func NextConflict(faces []*Face) (int, int) {
 // FindExtremums returns the 6 indices and 6 vec3 of the extremums for each axis
 // fomatted [minx, miny, minz, maxx, maxy, maxz]
 func FindExtremums(points []glm.Vec3) (extremumIndices [6]int, extremums [6]glm.Vec3) {
-	extremums = [6]glm.Vec3{
-		{math.MaxFloat32, 0, 0}, {0, math.MaxFloat32, 0}, {0, 0, math.MaxFloat32},
-		{-math.MaxFloat32, 0, 0}, {0, -math.MaxFloat32, 0}, {0, 0, -math.MaxFloat32},
+	for n := range extremums {
+		extremums[n] = points[0]
 	}
 	for i := range points {
 		for n := 0; n < 3; n++ {
@@ -126,27 +136,11 @@ func FindExtremums(points []glm.Vec3) (extremumIndices [6]int, extremums [6]glm.
 				extremums[n] = points[i]
 				extremumIndices[n] = i
 			}
-			if extremums[n][n] > points[i][n] {
-				extremums[n] = points[i]
-				extremumIndices[n] = i
-			}
-			if extremums[n][n] > points[i][n] {
-				extremums[n] = points[i]
-				extremumIndices[n] = i
-			}
 
 			if extremums[3+n][n] < points[i][n] {
 				extremums[3+n] = points[i]
 				extremumIndices[3+n] = i
 			}
-			if extremums[3+n][n] < points[i][n] {
-				extremums[3+n] = points[i]
-				extremumIndices[3+n] = i
-			}
-			if extremums[3+n][n] < points[i][n] {
-				extremums[3+n] = points[i]
-				extremumIndices[3+n] = i
-			}
 		}
 	}
 	return
@@ -162,81 +156,27 @@ func CalculateEpsilon(extremums [6]glm.Vec3) float32 {
 	return epsilonbase * maxima * 3
 }
 
-// BuildInitialTetrahedron builds the initial tetrahedron from the given 4 indices
+// BuildInitialTetrahedron builds the initial tetrahedron from the given 4
+// indices. d must be on the side of {a b c} opposite to its normal, that is
+// (d-a).((c-a)x(b-a)) < 0.
 func BuildInitialTetrahedron(a, b, c, d int, points []glm.Vec3) []*Face {
-	ab := points[b].Sub(&points[a])
-	ac := points[c].Sub(&points[a])
-	cd := points[d].Sub(&points[c])
-	ca := points[a].Sub(&points[c])
-	ba := points[a].Sub(&points[b])
-	bd := points[d].Sub(&points[b])
-	dc := points[c].Sub(&points[d])
-	db := points[b].Sub(&points[d])
-
-	f0 := &Face{Vertices: [3]int{a, b, c}, Normal: ac.Cross(&ab), Point: points[a]}
-	f1 := &Face{Vertices: [3]int{c, d, a}, Normal: ca.Cross(&cd), Point: points[c]}
-	f2 := &Face{Vertices: [3]int{b, a, d}, Normal: bd.Cross(&ba), Point: points[b]}
-	f3 := &Face{Vertices: [3]int{d, c, b}, Normal: db.Cross(&dc), Point: points[d]}
-
-	f0.Faces = [3]*Face{f2, f3, f1}
-	f1.Faces = [3]*Face{f3, f2, f0}
-	f2.Faces = [3]*Face{f0, f1, f3}
-	f3.Faces = [3]*Face{f1, f0, f2}
-
-	// edges of f0
-	e00 := &Edge{Tail: a, Face: f0}
-	e01 := &Edge{Tail: b, Face: f0}
-	e02 := &Edge{Tail: c, Face: f0}
-
-	// edges of f1
-	e10 := &Edge{Tail: c, Face: f1}
-	e11 := &Edge{Tail: d, Face: f1}
-	e12 := &Edge{Tail: a, Face: f1}
-
-	// edges of f2
-	e20 := &Edge{Tail: b, Face: f2}
-	e21 := &Edge{Tail: a, Face: f2}
-	e22 := &Edge{Tail: d, Face: f2}
-
-	// edges of f3
-	e30 := &Edge{Tail: d, Face: f3}
-	e31 := &Edge{Tail: c, Face: f3}
-	e32 := &Edge{Tail: b, Face: f3}
-
-	// Connect the faces to the edges
-	f0.Edges = [3]*Edge{e00, e01, e02}
-	f1.Edges = [3]*Edge{e10, e11, e12}
-	f2.Edges = [3]*Edge{e20, e21, e22}
-	f3.Edges = [3]*Edge{e30, e31, e32}
+	f0 := NewFace(a, b, c, points)
+	f1 := NewFace(c, d, a, points)
+	f2 := NewFace(b, a, d, points)
+	f3 := NewFace(d, c, b, points)
 
 	//Setup twin edges
-	e00.Twin, e20.Twin = e20, e00
-	e01.Twin, e31.Twin = e31, e01
-	e02.Twin, e12.Twin = e12, e02
-	e10.Twin, e30.Twin = e30, e10
-	e11.Twin, e21.Twin = e21, e11
-	e22.Twin, e32.Twin = e32, e22
-
-	// Circular connect the edges
-	// e0*
-	e00.Next, e00.Prev = e01, e02
-	e01.Next, e01.Prev = e02, e00
-	e02.Next, e02.Prev = e00, e01
-
-	// e1*
-	e10.Next, e10.Prev = e11, e12
-	e11.Next, e11.Prev = e12, e10
-	e12.Next, e12.Prev = e10, e11
-
-	// e2*
-	e20.Next, e20.Prev = e21, e22
-	e21.Next, e21.Prev = e22, e20
-	e22.Next, e22.Prev = e20, e21
-
-	// e3*
-	e30.Next, e30.Prev = e31, e32
-	e31.Next, e31.Prev = e32, e30
-	e32.Next, e32.Prev = e30, e31
+	Link(f0.Edges[0], f2.Edges[0]) // a-b
+	Link(f0.Edges[1], f3.Edges[1]) // b-c
+	Link(f0.Edges[2], f1.Edges[2]) // c-a
+	Link(f1.Edges[0], f3.Edges[0]) // c-d
+	Link(f1.Edges[1], f2.Edges[1]) // d-a
+	Link(f2.Edges[2], f3.Edges[2]) // d-b
 
 	return []*Face{f0, f1, f2, f3}
 }
+
+// Link makes e0 and e1 twins of each other.
+func Link(e0, e1 *Edge) {
+	e0.Twin, e1.Twin = e1, e0
+}
@@ -0,0 +1,319 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// KDTree is a balanced k-d tree over a set of 3D points. Queries return the
+// indices of the points in the slice the tree was built from.
+type KDTree struct {
+	tree kdTree
+}
+
+// NewKDTree builds a k-d tree over points. The points are copied, the tree
+// isn't affected by later modifications of the slice.
+func NewKDTree(points []glm.Vec3) *KDTree {
+	coords := make([]float32, 0, 3*len(points))
+	for n := range points {
+		coords = append(coords, points[n][:]...)
+	}
+	t := &KDTree{}
+	t.tree.build(3, coords)
+	return t
+}
+
+// Nearest returns the index of the point closest to p and its distance to p.
+// It returns false if the tree is empty.
+func (t *KDTree) Nearest(p *glm.Vec3) (index int, distance float32, ok bool) {
+	return t.tree.nearest(p[:])
+}
+
+// KNearest returns the indices of the k points closest to p, sorted from the
+// closest. It returns fewer indices if the tree has fewer than k points.
+func (t *KDTree) KNearest(p *glm.Vec3, k int) []int {
+	return t.tree.kNearest(p[:], k)
+}
+
+// Radius returns the indices of the points within radius of p, in no
+// particular order.
+func (t *KDTree) Radius(p *glm.Vec3, radius float32) []int {
+	return t.tree.radius(p[:], radius)
+}
+
+// KDTree2 is a balanced k-d tree over a set of 2D points. Queries return the
+// indices of the points in the slice the tree was built from.
+type KDTree2 struct {
+	tree kdTree
+}
+
+// NewKDTree2 builds a k-d tree over points. The points are copied, the tree
+// isn't affected by later modifications of the slice.
+func NewKDTree2(points []glm.Vec2) *KDTree2 {
+	coords := make([]float32, 0, 2*len(points))
+	for n := range points {
+		coords = append(coords, points[n][:]...)
+	}
+	t := &KDTree2{}
+	t.tree.build(2, coords)
+	return t
+}
+
+// Nearest returns the index of the point closest to p and its distance to p.
+// It returns false if the tree is empty.
+func (t *KDTree2) Nearest(p *glm.Vec2) (index int, distance float32, ok bool) {
+	return t.tree.nearest(p[:])
+}
+
+// KNearest returns the indices of the k points closest to p, sorted from the
+// closest. It returns fewer indices if the tree has fewer than k points.
+func (t *KDTree2) KNearest(p *glm.Vec2, k int) []int {
+	return t.tree.kNearest(p[:], k)
+}
+
+// Radius returns the indices of the points within radius of p, in no
+// particular order.
+func (t *KDTree2) Radius(p *glm.Vec2, radius float32) []int {
+	return t.tree.radius(p[:], radius)
+}
+
+// kdTree is a k-d tree of any dimension stored implicitly: the node of the
+// range [lo, hi) of indices is at (lo+hi)/2, its children are the ranges on
+// each side of it.
+type kdTree struct {
+	dim int
+
+	// the coordinates of point n are coords[n*dim : n*dim+dim].
+	coords []float32
+
+	// the indices of the points, in tree order, and the splitting axis of
+	// every node.
+	indices []int
+	axes    []uint8
+}
+
+// build builds the tree over coords, made of points of dimension dim.
+func (t *kdTree) build(dim int, coords []float32) {
+	t.dim, t.coords = dim, coords
+	t.indices = make([]int, len(coords)/dim)
+	t.axes = make([]uint8, len(t.indices))
+	for n := range t.indices {
+		t.indices[n] = n
+	}
+	t.split(0, len(t.indices))
+}
+
+// split makes the median of [lo, hi) along the axis of largest spread the
+// node of the range and splits its children.
+func (t *kdTree) split(lo, hi int) {
+	if hi-lo <= 1 {
+		return
+	}
+
+	var axis int
+	var spread float32 = -1
+	for i := 0; i < t.dim; i++ {
+		min, max := float32(math.MaxFloat32), float32(-math.MaxFloat32)
+		for _, n := range t.indices[lo:hi] {
+			min = math.Min(min, t.coord(n, i))
+			max = math.Max(max, t.coord(n, i))
+		}
+		if max-min > spread {
+			axis, spread = i, max-min
+		}
+	}
+
+	mid := (lo + hi) / 2
+	t.selectNth(lo, hi, mid, axis)
+	t.axes[mid] = uint8(axis)
+	t.split(lo, mid)
+	t.split(mid+1, hi)
+}
+
+// selectNth reorders [lo, hi) so the point at nth is the one that would be
+// there if the range was sorted along axis, with no point after it smaller
+// and no point before it larger.
+func (t *kdTree) selectNth(lo, hi, nth, axis int) {
+	for hi-lo > 1 {
+		// Partition around the median of 3 of the range.
+		a, b, c := t.coord(t.indices[lo], axis), t.coord(t.indices[(lo+hi)/2], axis), t.coord(t.indices[hi-1], axis)
+		pivot := math.Max(math.Min(a, b), math.Min(math.Max(a, b), c))
+
+		i, j := lo, hi-1
+		for i <= j {
+			for t.coord(t.indices[i], axis) < pivot {
+				i++
+			}
+			for t.coord(t.indices[j], axis) > pivot {
+				j--
+			}
+			if i <= j {
+				t.indices[i], t.indices[j] = t.indices[j], t.indices[i]
+				i++
+				j--
+			}
+		}
+
+		// [lo, j] <= pivot, [i, hi) >= pivot and (j, i) == pivot.
+		switch {
+		case nth <= j:
+			hi = j + 1
+		case nth >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
+
+// coord returns coordinate i of point n.
+func (t *kdTree) coord(n, i int) float32 {
+	return t.coords[n*t.dim+i]
+}
+
+// dist2 returns the square distance of point n to p.
+func (t *kdTree) dist2(n int, p []float32) float32 {
+	var d2 float32
+	for i, c := range t.coords[n*t.dim : n*t.dim+t.dim] {
+		d := c - p[i]
+		d2 += d * d
+	}
+	return d2
+}
+
+// search visits the nodes of the tree that might contain a point closer to p
+// than the square distance returned by visit, closest nodes first. visit is
+// called with every node visited and returns the new search distance.
+func (t *kdTree) search(p []float32, max2 float32, visit func(n int, d2 float32) float32) {
+	t.searchRange(0, len(t.indices), p, max2, visit)
+}
+
+func (t *kdTree) searchRange(lo, hi int, p []float32, max2 float32, visit func(n int, d2 float32) float32) float32 {
+	if lo >= hi {
+		return max2
+	}
+	mid := (lo + hi) / 2
+	n := t.indices[mid]
+	max2 = visit(n, t.dist2(n, p))
+
+	// Visit the side of p first, then the other side if the splitting plane
+	// is closer than the search distance.
+	axis := int(t.axes[mid])
+	d := p[axis] - t.coord(n, axis)
+	if d < 0 {
+		max2 = t.searchRange(lo, mid, p, max2, visit)
+		if d*d <= max2 {
+			max2 = t.searchRange(mid+1, hi, p, max2, visit)
+		}
+	} else {
+		max2 = t.searchRange(mid+1, hi, p, max2, visit)
+		if d*d <= max2 {
+			max2 = t.searchRange(lo, mid, p, max2, visit)
+		}
+	}
+	return max2
+}
+
+// nearest returns the point closest to p and its distance.
+func (t *kdTree) nearest(p []float32) (index int, distance float32, ok bool) {
+	index = -1
+	best := float32(math.MaxFloat32)
+	t.search(p, best, func(n int, d2 float32) float32 {
+		if d2 < best || !ok {
+			index, best, ok = n, d2, true
+		}
+		return best
+	})
+	if ok {
+		distance = math.Sqrt(best)
+	}
+	return
+}
+
+// kNearest returns the k points closest to p, closest first.
+func (t *kdTree) kNearest(p []float32, k int) []int {
+	if k <= 0 {
+		return nil
+	}
+
+	// heap is a max heap of the k closest points found so far.
+	var heap kdHeap
+	t.search(p, math.MaxFloat32, func(n int, d2 float32) float32 {
+		if len(heap) < k {
+			heap.push(kdCandidate{n, d2})
+		} else if d2 < heap[0].d2 {
+			heap[0] = kdCandidate{n, d2}
+			heap.down(0)
+		}
+		if len(heap) < k {
+			return math.MaxFloat32
+		}
+		return heap[0].d2
+	})
+
+	indices := make([]int, len(heap))
+	for n := len(heap) - 1; n >= 0; n-- {
+		indices[n] = heap.pop().index
+	}
+	return indices
+}
+
+// radius returns the points within radius of p.
+func (t *kdTree) radius(p []float32, radius float32) []int {
+	var indices []int
+	r2 := radius * radius
+	t.search(p, r2, func(n int, d2 float32) float32 {
+		if d2 <= r2 {
+			indices = append(indices, n)
+		}
+		return r2
+	})
+	return indices
+}
+
+// kdCandidate is a point found by a k-nearest query.
+type kdCandidate struct {
+	index int
+	d2    float32
+}
+
+// kdHeap is a binary max heap of candidates ordered by distance.
+type kdHeap []kdCandidate
+
+func (h *kdHeap) push(c kdCandidate) {
+	*h = append(*h, c)
+	for n := len(*h) - 1; n > 0; {
+		parent := (n - 1) / 2
+		if (*h)[parent].d2 >= (*h)[n].d2 {
+			break
+		}
+		(*h)[parent], (*h)[n] = (*h)[n], (*h)[parent]
+		n = parent
+	}
+}
+
+func (h *kdHeap) pop() kdCandidate {
+	top := (*h)[0]
+	last := len(*h) - 1
+	(*h)[0] = (*h)[last]
+	*h = (*h)[:last]
+	h.down(0)
+	return top
+}
+
+// down moves the candidate at n down until the heap is valid.
+func (h kdHeap) down(n int) {
+	for {
+		largest := n
+		for _, child := range [2]int{2*n + 1, 2*n + 2} {
+			if child < len(h) && h[child].d2 > h[largest].d2 {
+				largest = child
+			}
+		}
+		if largest == n {
+			return
+		}
+		h[n], h[largest] = h[largest], h[n]
+		n = largest
+	}
+}
@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestKDTree(t *testing.T) {
+	t.Parallel()
+	points := []glm.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 2, 0}, {0, 0, 3}, {5, 5, 5}, {1, 0, 0.5}}
+	tree := NewKDTree(points)
+
+	tests := []struct {
+		p        glm.Vec3
+		nearest  int
+		distance float32
+		k        int
+		knearest []int
+		radius   float32
+		inRadius []int
+	}{
+		{glm.Vec3{0.1, 0, 0}, 0, 0.1, 3, []int{0, 1, 5}, 1, []int{0, 1}},
+		{glm.Vec3{6, 6, 6}, 4, 1.7320508, 2, []int{4, 3}, 1, nil},
+		{glm.Vec3{0, 1.9, 0}, 2, 0.1, 1, []int{2}, 2, []int{0, 2}},
+		{glm.Vec3{0, 0, 0}, 0, 0, 10, []int{0, 1, 5, 2, 3, 4}, 0, []int{0}},
+	}
+
+	for i, test := range tests {
+		index, distance, ok := tree.Nearest(&test.p)
+		if !ok || index != test.nearest || !glm.FloatEqualThreshold(distance, test.distance, 1e-4) {
+			t.Errorf("[%d] Nearest = %d at %f, want %d at %f", i, index, distance, test.nearest, test.distance)
+		}
+		knearest := tree.KNearest(&test.p, test.k)
+		if !equalInts(knearest, test.knearest) {
+			t.Errorf("[%d] KNearest = %v, want %v", i, knearest, test.knearest)
+		}
+		inRadius := tree.Radius(&test.p, test.radius)
+		sort.Ints(inRadius)
+		if !equalInts(inRadius, test.inRadius) {
+			t.Errorf("[%d] Radius = %v, want %v", i, inRadius, test.inRadius)
+		}
+	}
+}
+
+func TestKDTree_Empty(t *testing.T) {
+	t.Parallel()
+	tree := NewKDTree(nil)
+	if _, _, ok := tree.Nearest(&glm.Vec3{}); ok {
+		t.Errorf("Nearest on an empty tree found a point")
+	}
+	if knearest := tree.KNearest(&glm.Vec3{}, 3); len(knearest) != 0 {
+		t.Errorf("KNearest on an empty tree = %v", knearest)
+	}
+}
+
+func TestKDTree_BruteForce(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	points := make([]glm.Vec3, 1000)
+	for n := range points {
+		points[n] = glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}
+		if n%10 == 0 {
+			// Some points on a grid to have equal coordinates.
+			points[n] = glm.Vec3{float32(n % 7), float32(n % 3), 0}
+		}
+	}
+	tree := NewKDTree(points)
+
+	for n := 0; n < 200; n++ {
+		p := glm.Vec3{r.Float32()*120 - 60, r.Float32()*120 - 60, r.Float32()*120 - 60}
+		dist2 := make([]float32, len(points))
+		order := make([]int, len(points))
+		for m := range points {
+			d := points[m].Sub(&p)
+			dist2[m], order[m] = d.Len2(), m
+		}
+		sort.SliceStable(order, func(i, j int) bool { return dist2[order[i]] < dist2[order[j]] })
+
+		index, _, _ := tree.Nearest(&p)
+		if dist2[index] != dist2[order[0]] {
+			t.Errorf("[%d] Nearest = %d, want %d", n, index, order[0])
+		}
+
+		k := 1 + r.Intn(20)
+		for m, index := range tree.KNearest(&p, k) {
+			if dist2[index] != dist2[order[m]] {
+				t.Errorf("[%d] KNearest[%d] = %d, want %d", n, m, index, order[m])
+			}
+		}
+
+		radius := r.Float32() * 20
+		var want []int
+		for m := range points {
+			if dist2[m] <= radius*radius {
+				want = append(want, m)
+			}
+		}
+		got := tree.Radius(&p, radius)
+		sort.Ints(got)
+		if !equalInts(got, want) {
+			t.Errorf("[%d] Radius = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestKDTree2(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	points := make([]glm.Vec2, 500)
+	for n := range points {
+		points[n] = glm.Vec2{r.Float32()*100 - 50, r.Float32()*100 - 50}
+	}
+	tree := NewKDTree2(points)
+
+	for n := 0; n < 100; n++ {
+		p := glm.Vec2{r.Float32()*120 - 60, r.Float32()*120 - 60}
+		best := -1
+		var bestDist2 float32
+		var want []int
+		for m := range points {
+			d := points[m].Sub(&p)
+			if d2 := d.Len2(); best < 0 || d2 < bestDist2 {
+				best, bestDist2 = m, d2
+			}
+			if d.Len2() <= 100 {
+				want = append(want, m)
+			}
+		}
+
+		if index, _, _ := tree.Nearest(&p); index != best {
+			t.Errorf("[%d] Nearest = %d, want %d", n, index, best)
+		}
+		if knearest := tree.KNearest(&p, 1); len(knearest) != 1 || knearest[0] != best {
+			t.Errorf("[%d] KNearest = %v, want [%d]", n, knearest, best)
+		}
+		got := tree.Radius(&p, 10)
+		sort.Ints(got)
+		if !equalInts(got, want) {
+			t.Errorf("[%d] Radius = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func BenchmarkKDTree_KNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	points := make([]glm.Vec3, 10000)
+	for n := range points {
+		points[n] = glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}
+	}
+	tree := NewKDTree(points)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.KNearest(&points[i%len(points)], 8)
+	}
+}
@@ -0,0 +1,41 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+)
+
+// Contact is a point where 2 shapes touch.
+type Contact struct {
+	// Point is the position of the contact, halfway between the surfaces of
+	// the 2 shapes.
+	Point glm.Vec3
+
+	// Depth is the penetration of the shapes at Point along the normal of the
+	// manifold.
+	Depth float32
+}
+
+// Manifold is the set of contacts between 2 shapes.
+type Manifold struct {
+	// Normal is the unit contact normal, it points from the first shape toward
+	// the second.
+	Normal glm.Vec3
+
+	// Axis identifies the separating axis of minimum penetration that produced
+	// Normal. Its meaning depends on the function that built the manifold.
+	Axis int
+
+	// the contacts of the manifold. Data past Contacts[Size] is assumed to be
+	// garbage.
+	Contacts [4]Contact
+
+	// Size is the amount of contacts, 0 if the shapes don't touch.
+	Size int
+}
+
+// add appends a contact to the manifold. This will panic if you add a 5th
+// contact.
+func (m *Manifold) add(point *glm.Vec3, depth float32) {
+	m.Contacts[m.Size] = Contact{Point: *point, Depth: depth}
+	m.Size++
+}
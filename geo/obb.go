@@ -75,7 +75,7 @@ func TestOBBOBB(a, b *OBB) bool {
 
 	for i := 0; i < 3; i++ {
 		for j := 0; j < 3; j++ {
-			R[j*3+i] = a.Orientation[i].Dot(&b.Orientation[i])
+			R[j*3+i] = a.Orientation[i].Dot(&b.Orientation[j])
 		}
 	}
 
@@ -179,3 +179,286 @@ func TestOBBOBB(a, b *OBB) bool {
 
 	return true
 }
+
+// Support returns the point of the OBB furthest along direction.
+func (o *OBB) Support(direction glm.Vec3) glm.Vec3 {
+	p := o.Center
+	for i := 0; i < 3; i++ {
+		if direction.Dot(&o.Orientation[i]) < 0 {
+			p.AddScaledVec(-o.HalfExtend[i], &o.Orientation[i])
+		} else {
+			p.AddScaledVec(o.HalfExtend[i], &o.Orientation[i])
+		}
+	}
+	return p
+}
+
+// obbAxisTolerance biases CollideOBBOBB toward the faces of a, then the faces
+// of b, then the edges, so the manifold doesn't flip between frames when 2 axes
+// have almost the same penetration.
+const obbAxisTolerance = 0.95
+
+// CollideOBBOBB returns the contact manifold of 2 OBB. Manifold.Axis is the
+// axis of minimum penetration: 0 to 2 are the axes of a, 3 to 5 the axes of b
+// and 6+3*i+j is the cross product of the axis i of a and the axis j of b.
+// Face contacts clip the incident face against the reference face and produce
+// up to 4 contacts, edge contacts produce 1. The manifold is empty if the OBB
+// don't overlap.
+func CollideOBBOBB(a, b *OBB) Manifold {
+	// The cross products of unit axes shorter than epsilon come from nearly
+	// parallel edges, their direction is mostly rounding error.
+	const (
+		epsilon = 0.0001
+	)
+
+	// R expresses b in the coordinate frame of a, its column j is the axis j
+	// of b.
+	var R glm.Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			R[j*3+i] = a.Orientation[i].Dot(&b.Orientation[j])
+		}
+	}
+
+	// Compute translation vector d and bring it into a's coordinate frame
+	d := b.Center.Sub(&a.Center)
+	t := glm.Vec3{d.Dot(&a.Orientation[0]), d.Dot(&a.Orientation[1]), d.Dot(&a.Orientation[2])}
+
+	// separation returns the distance between the projections of the OBB on l,
+	// given in a's coordinate frame, scaled by the length of l.
+	separation := func(l *glm.Vec3) float32 {
+		ra := a.HalfExtend[0]*math.Abs(l[0]) + a.HalfExtend[1]*math.Abs(l[1]) + a.HalfExtend[2]*math.Abs(l[2])
+		var rb float32
+		for k := 0; k < 3; k++ {
+			bk := R.Col(k)
+			rb += b.HalfExtend[k] * math.Abs(l.Dot(&bk))
+		}
+		return math.Abs(t.Dot(l)) - ra - rb
+	}
+
+	// Find the axis of minimum penetration of each kind.
+	var best [3]float32
+	var axes [3]int
+	for n := range best {
+		best[n] = -math.MaxFloat32
+	}
+	for n := 0; n < 15; n++ {
+		var l glm.Vec3
+		kind := 0
+		switch {
+		case n < 3:
+			l[n] = 1
+		case n < 6:
+			l = R.Col(n - 3)
+			kind = 1
+		default:
+			ai, bj := glm.Vec3{}, R.Col((n-6)%3)
+			ai[(n-6)/3] = 1
+			l = ai.Cross(&bj)
+			kind = 2
+		}
+
+		length := l.Len()
+		if length < epsilon {
+			// The edges are parallel, one of the face axes separates them.
+			continue
+		}
+		s := separation(&l) / length
+		if s > 0 {
+			return Manifold{}
+		}
+		if s > best[kind] {
+			best[kind], axes[kind] = s, n
+		}
+	}
+
+	depth, axis := best[0], axes[0]
+	for kind := 1; kind < 3; kind++ {
+		if best[kind] > obbAxisTolerance*depth {
+			depth, axis = best[kind], axes[kind]
+		}
+	}
+
+	m := Manifold{Axis: axis}
+	switch {
+	case axis < 3:
+		m.Normal = a.Orientation[axis]
+	case axis < 6:
+		m.Normal = b.Orientation[axis-3]
+	default:
+		m.Normal = a.Orientation[(axis-6)/3].Cross(&b.Orientation[(axis-6)%3])
+		m.Normal.Normalize()
+	}
+	if m.Normal.Dot(&d) < 0 {
+		m.Normal.Invert()
+	}
+
+	switch {
+	case axis < 3:
+		clipOBBFace(a, b, axis, &m.Normal, &m)
+	case axis < 6:
+		normal := m.Normal.Inverse()
+		clipOBBFace(b, a, axis-3, &normal, &m)
+	default:
+		i, j := (axis-6)/3, (axis-6)%3
+		n := m.Normal.Inverse()
+		pa, qa := obbEdge(a, i, &m.Normal)
+		pb, qb := obbEdge(b, j, &n)
+		_, _, _, ca, cb := ClosestPointSegmentSegment(&pa, &qa, &pb, &qb)
+		p := ca.Add(&cb)
+		p = p.Mul(0.5)
+		m.add(&p, -depth)
+	}
+	return m
+}
+
+// obbEdge returns the edge of the OBB parallel to its axis i that is the
+// furthest along direction.
+func obbEdge(o *OBB, i int, direction *glm.Vec3) (p, q glm.Vec3) {
+	center := o.Center
+	for k := 0; k < 3; k++ {
+		if k == i {
+			continue
+		}
+		if direction.Dot(&o.Orientation[k]) < 0 {
+			center.AddScaledVec(-o.HalfExtend[k], &o.Orientation[k])
+		} else {
+			center.AddScaledVec(o.HalfExtend[k], &o.Orientation[k])
+		}
+	}
+	p, q = center, center
+	p.AddScaledVec(-o.HalfExtend[i], &o.Orientation[i])
+	q.AddScaledVec(o.HalfExtend[i], &o.Orientation[i])
+	return
+}
+
+// clipOBBFace clips the face of inc the most anti-parallel to normal against
+// the face of ref along its axis refAxis and adds the resulting contacts to m.
+// normal is the axis refAxis of ref pointing toward inc.
+func clipOBBFace(ref, inc *OBB, refAxis int, normal *glm.Vec3, m *Manifold) {
+	// Find the incident face.
+	var incAxis int
+	var maxDot float32
+	for k := 0; k < 3; k++ {
+		if dot := math.Abs(normal.Dot(&inc.Orientation[k])); dot > maxDot {
+			maxDot, incAxis = dot, k
+		}
+	}
+	incNormal := inc.Orientation[incAxis]
+	if incNormal.Dot(normal) > 0 {
+		incNormal.Invert()
+	}
+	incCenter := inc.Center
+	incCenter.AddScaledVec(inc.HalfExtend[incAxis], &incNormal)
+
+	u, v := (incAxis+1)%3, (incAxis+2)%3
+	var buf0, buf1 [8]glm.Vec3
+	polygon := buf0[:0]
+	for _, sign := range [4][2]float32{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}} {
+		p := incCenter
+		p.AddScaledVec(sign[0]*inc.HalfExtend[u], &inc.Orientation[u])
+		p.AddScaledVec(sign[1]*inc.HalfExtend[v], &inc.Orientation[v])
+		polygon = append(polygon, p)
+	}
+
+	// Clip it against the 4 side planes of the reference face.
+	for _, k := range [2]int{(refAxis + 1) % 3, (refAxis + 2) % 3} {
+		side := ref.Orientation[k]
+		offset := side.Dot(&ref.Center)
+		polygon = clipPolygon(polygon, &side, offset+ref.HalfExtend[k], buf1[:0])
+		side.Invert()
+		polygon = clipPolygon(polygon, &side, -offset+ref.HalfExtend[k], buf0[:0])
+	}
+
+	// Keep the points below the reference face.
+	var points [8]glm.Vec3
+	var depths [8]float32
+	var size int
+	refOffset := normal.Dot(&ref.Center) + ref.HalfExtend[refAxis]
+	for n := range polygon {
+		depth := refOffset - normal.Dot(&polygon[n])
+		if depth < 0 {
+			continue
+		}
+		points[size] = polygon[n]
+		points[size].AddScaledVec(depth/2, normal)
+		depths[size] = depth
+		size++
+	}
+
+	for _, n := range reduceContacts(points[:size], depths[:size], normal) {
+		m.add(&points[n], depths[n])
+	}
+}
+
+// clipPolygon clips the polygon against the half-space n.p <= offset and
+// appends the result to out.
+func clipPolygon(polygon []glm.Vec3, n *glm.Vec3, offset float32, out []glm.Vec3) []glm.Vec3 {
+	for i := range polygon {
+		p, q := &polygon[i], &polygon[(i+1)%len(polygon)]
+		dp, dq := n.Dot(p)-offset, n.Dot(q)-offset
+		if dp <= 0 {
+			out = append(out, *p)
+		}
+		if (dp < 0 && dq > 0) || (dp > 0 && dq < 0) {
+			pq := q.Sub(p)
+			x := *p
+			x.AddScaledVec(dp/(dp-dq), &pq)
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// reduceContacts returns the indices of at most 4 of the points. It keeps the
+// deepest one and the ones that span the biggest area around it.
+func reduceContacts(points []glm.Vec3, depths []float32, normal *glm.Vec3) []int {
+	if len(points) <= 4 {
+		indices := make([]int, len(points))
+		for n := range indices {
+			indices[n] = n
+		}
+		return indices
+	}
+
+	var i0 int
+	for n := range depths {
+		if depths[n] > depths[i0] {
+			i0 = n
+		}
+	}
+
+	var i1 int
+	var maxDist float32 = -1
+	for n := range points {
+		v := points[n].Sub(&points[i0])
+		if dist := v.Len2(); dist > maxDist {
+			maxDist, i1 = dist, n
+		}
+	}
+
+	// The 2 last points are the furthest on either side of {i0 i1}.
+	var i2, i3 int
+	var maxArea, minArea float32
+	edge := points[i1].Sub(&points[i0])
+	for n := range points {
+		v := points[n].Sub(&points[i0])
+		c := edge.Cross(&v)
+		area := c.Dot(normal)
+		if area > maxArea {
+			maxArea, i2 = area, n
+		}
+		if area < minArea {
+			minArea, i3 = area, n
+		}
+	}
+
+	indices := []int{i0, i1}
+	if maxArea > 0 {
+		indices = append(indices, i2)
+	}
+	if minArea < 0 {
+		indices = append(indices, i3)
+	}
+	return indices
+}
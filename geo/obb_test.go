@@ -0,0 +1,143 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+// rotatedOBB returns an OBB rotated by angle around axis.
+func rotatedOBB(center glm.Vec3, halfExtend glm.Vec3, angle float32, axis glm.Vec3) OBB {
+	q := glm.QuatRotate(angle, &axis)
+	m := q.Mat4()
+	return OBB{
+		Center: center,
+		Orientation: [3]glm.Vec3{
+			{m[0], m[1], m[2]},
+			{m[4], m[5], m[6]},
+			{m[8], m[9], m[10]},
+		},
+		HalfExtend: halfExtend,
+	}
+}
+
+func TestTestOBBOBB(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b      OBB
+		intersect bool
+	}{
+		{ // 0
+			a:         rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:         rotatedOBB(glm.Vec3{0, 1.9, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			intersect: true,
+		},
+		{ // 1
+			a:         rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:         rotatedOBB(glm.Vec3{0, 2.1, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			intersect: false,
+		},
+		{ // 2 only separated along an axis of b
+			a:         rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:         rotatedOBB(glm.Vec3{2.2, 2.2, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1}),
+			intersect: false,
+		},
+		{ // 3
+			a:         rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:         rotatedOBB(glm.Vec3{1.6, 1.6, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1}),
+			intersect: true,
+		},
+	}
+
+	for i, test := range tests {
+		if intersect := TestOBBOBB(&test.a, &test.b); intersect != test.intersect {
+			t.Errorf("[%d] intersect = %t, want %t", i, intersect, test.intersect)
+		}
+	}
+}
+
+func TestCollideOBBOBB(t *testing.T) {
+	t.Parallel()
+	const sqrt2 = 1.4142135
+	tests := []struct {
+		a, b     OBB
+		axis     int
+		normal   glm.Vec3
+		contacts []Contact
+	}{
+		{ // 0 box resting on another
+			a:      rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:      rotatedOBB(glm.Vec3{0, 1.9, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			axis:   1,
+			normal: glm.Vec3{0, 1, 0},
+			contacts: []Contact{
+				{Point: glm.Vec3{1, 0.95, 1}, Depth: 0.1},
+				{Point: glm.Vec3{-1, 0.95, 1}, Depth: 0.1},
+				{Point: glm.Vec3{-1, 0.95, -1}, Depth: 0.1},
+				{Point: glm.Vec3{1, 0.95, -1}, Depth: 0.1},
+			},
+		},
+		{ // 1 the clipped face is an octagon
+			a:      rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b:      rotatedOBB(glm.Vec3{0, 1.9, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 1, 0}),
+			axis:   1,
+			normal: glm.Vec3{0, 1, 0},
+			contacts: []Contact{
+				{Point: glm.Vec3{1, 0.95, 1 - sqrt2}, Depth: 0.1},
+				{Point: glm.Vec3{-1, 0.95, sqrt2 - 1}, Depth: 0.1},
+				{Point: glm.Vec3{sqrt2 - 1, 0.95, 1}, Depth: 0.1},
+				{Point: glm.Vec3{1 - sqrt2, 0.95, -1}, Depth: 0.1},
+			},
+		},
+		{ // 2 edge of a against face of b
+			a:      rotatedOBB(glm.Vec3{0, sqrt2 - 0.1, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1}),
+			b:      rotatedOBB(glm.Vec3{0, -1, 0}, glm.Vec3{5, 1, 5}, 0, glm.Vec3{0, 1, 0}),
+			axis:   4,
+			normal: glm.Vec3{0, -1, 0},
+			contacts: []Contact{
+				{Point: glm.Vec3{0, -0.05, 1}, Depth: 0.1},
+				{Point: glm.Vec3{0, -0.05, -1}, Depth: 0.1},
+			},
+		},
+		{ // 3 edge against edge
+			a:      rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{1, 0, 0}),
+			b:      rotatedOBB(glm.Vec3{0, 2*sqrt2 - 0.1, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1}),
+			axis:   8,
+			normal: glm.Vec3{0, 1, 0},
+			contacts: []Contact{
+				{Point: glm.Vec3{0, sqrt2 - 0.05, 0}, Depth: 0.1},
+			},
+		},
+		{ // 4 separated
+			a: rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, 0, glm.Vec3{0, 1, 0}),
+			b: rotatedOBB(glm.Vec3{2.2, 2.2, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1}),
+		},
+	}
+
+	for i, test := range tests {
+		m := CollideOBBOBB(&test.a, &test.b)
+		if m.Size != len(test.contacts) {
+			t.Errorf("[%d] %d contacts, want %d", i, m.Size, len(test.contacts))
+			continue
+		}
+		if m.Size == 0 {
+			continue
+		}
+		if m.Axis != test.axis || !m.Normal.EqualThreshold(&test.normal, 1e-4) {
+			t.Errorf("[%d] axis = %d, normal = %v, want %d, %v", i, m.Axis, m.Normal, test.axis, test.normal)
+		}
+		// The order of the contacts doesn't matter.
+		for _, want := range test.contacts {
+			found := false
+			for _, c := range m.Contacts[:m.Size] {
+				d := c.Point.Sub(&want.Point)
+				if d.Len() < 1e-4 && math.Abs(c.Depth-want.Depth) < 1e-4 {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("[%d] contact %v not found in %v", i, want, m.Contacts[:m.Size])
+			}
+		}
+	}
+}
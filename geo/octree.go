@@ -0,0 +1,327 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+)
+
+// Octree is a loose octree of objects stored by their AABB. The cells of the
+// tree are cubes, every node divides its cell in 8 and its children are
+// created as needed. The bounds of a node are its cell scaled by the
+// looseness, so an object is stored in the deepest node whose bounds contain
+// it, its center being in the cell of the node. Objects outside the cell of
+// the root are stored in the root.
+type Octree struct {
+	nodes []octreeNode
+
+	// the head of the list of free nodes.
+	free int
+
+	objects     []octreeObject
+	freeObjects []int
+
+	looseness float32
+	maxDepth  int
+}
+
+// octreeNode is a node of an Octree.
+type octreeNode struct {
+	// the cell of the node and its loose bounds.
+	center   glm.Vec3
+	halfSize float32
+	bounds   AABB
+
+	depth    int
+	parent   int
+	children [8]int
+
+	// the objects stored in the node.
+	objects []int
+
+	// the amount of objects in the node and its descendants. Empty nodes are
+	// released.
+	count int
+
+	// next is the next free node when the node is in the free list.
+	next int
+}
+
+// octreeObject is an object of an Octree.
+type octreeObject struct {
+	aabb AABB
+	data interface{}
+
+	// the node the object is in, nullNode if it was removed.
+	node int
+}
+
+// NewOctree returns an empty octree whose root is the cube of side 2*halfSize
+// around center. The bounds of every node are its cell scaled by looseness,
+// which must be at least 1, 2 is a common value. Nodes are at most maxDepth
+// levels under the root.
+func NewOctree(center *glm.Vec3, halfSize, looseness float32, maxDepth int) *Octree {
+	o := &Octree{
+		free:      nullNode,
+		looseness: looseness,
+		maxDepth:  maxDepth,
+	}
+	o.allocate(center, halfSize, 0, nullNode)
+	return o
+}
+
+// Insert adds an object with the AABB aabb and returns its id. data can be
+// retrieved with Data.
+func (o *Octree) Insert(aabb *AABB, data interface{}) int {
+	var id int
+	if len(o.freeObjects) > 0 {
+		id = o.freeObjects[len(o.freeObjects)-1]
+		o.freeObjects = o.freeObjects[:len(o.freeObjects)-1]
+	} else {
+		id = len(o.objects)
+		o.objects = append(o.objects, octreeObject{})
+	}
+	o.objects[id] = octreeObject{aabb: *aabb, data: data}
+	o.link(id)
+	return id
+}
+
+// Remove removes the object. Its id might be reused by a future call to
+// Insert.
+func (o *Octree) Remove(id int) {
+	o.unlink(id)
+	o.objects[id] = octreeObject{node: nullNode}
+	o.freeObjects = append(o.freeObjects, id)
+}
+
+// Update changes the AABB of the object. The object only moves in the tree if
+// it doesn't belong to the same node anymore, in which case Update returns
+// true.
+func (o *Octree) Update(id int, aabb *AABB) bool {
+	obj := &o.objects[id]
+	obj.aabb = *aabb
+	if o.belongs(obj.node, aabb) {
+		return false
+	}
+	o.unlink(id)
+	o.link(id)
+	return true
+}
+
+// AABB returns the AABB of the object.
+func (o *Octree) AABB(id int) AABB {
+	return o.objects[id].aabb
+}
+
+// Data returns the user data of the object.
+func (o *Octree) Data(id int) interface{} {
+	return o.objects[id].data
+}
+
+// Depth returns the depth of the node the object is in, 0 for the root.
+func (o *Octree) Depth(id int) int {
+	return o.nodes[o.objects[id].node].depth
+}
+
+// Query calls callback with the id of every object whose AABB passes test.
+// Nodes whose bounds don't pass test are skipped with all their descendants,
+// so test must return true for every AABB that contains one that passes. The
+// query stops if callback returns false.
+func (o *Octree) Query(test func(aabb *AABB) bool, callback func(id int) bool) {
+	stack := []int{0}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// The root can have objects outside of its bounds.
+		node := &o.nodes[index]
+		if index != 0 && !test(&node.bounds) {
+			continue
+		}
+		for _, id := range node.objects {
+			if test(&o.objects[id].aabb) && !callback(id) {
+				return
+			}
+		}
+		for _, child := range node.children {
+			if child != nullNode {
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// QueryAABB calls callback with the id of every object whose AABB overlaps
+// aabb. The query stops if callback returns false.
+func (o *Octree) QueryAABB(aabb *AABB, callback func(id int) bool) {
+	o.Query(func(b *AABB) bool {
+		return TestAABBAABB(aabb, b)
+	}, callback)
+}
+
+// QuerySphere calls callback with the id of every object whose AABB
+// intersects s. The query stops if callback returns false.
+func (o *Octree) QuerySphere(s *Sphere, callback func(id int) bool) {
+	o.Query(func(b *AABB) bool {
+		return TestSphereAABB(s, b)
+	}, callback)
+}
+
+// QueryRay calls callback with the id of every object whose AABB is hit by
+// the ray before maxT. callback returns the new maxT, usually the t at which
+// the ray hits the object, to clip the ray. The query stops if it returns a
+// negative value.
+func (o *Octree) QueryRay(r *Ray, maxT float32, callback func(id int, maxT float32) float32) {
+	o.Query(func(b *AABB) bool {
+		_, ok := RaycastAABB(r, b, maxT)
+		return ok
+	}, func(id int) bool {
+		maxT = callback(id, maxT)
+		return maxT >= 0
+	})
+}
+
+// belongs returns true if an object with the AABB aabb would be stored in
+// node.
+func (o *Octree) belongs(index int, aabb *AABB) bool {
+	node := &o.nodes[index]
+	if index != 0 && !aabbContains(&node.bounds, aabb) {
+		return false
+	}
+	if index == 0 && !o.inCell(&node.center, node.halfSize, &aabb.Center) {
+		return true
+	}
+	if node.depth == o.maxDepth {
+		return true
+	}
+	center, halfSize := o.childCell(index, &aabb.Center)
+	bounds := o.looseBounds(&center, halfSize)
+	return !aabbContains(&bounds, aabb)
+}
+
+// link stores the object in the deepest node that can contain it, creating
+// the nodes as needed.
+func (o *Octree) link(id int) {
+	obj := &o.objects[id]
+	index := 0
+	if root := &o.nodes[0]; o.inCell(&root.center, root.halfSize, &obj.aabb.Center) {
+		for o.nodes[index].depth < o.maxDepth {
+			center, halfSize := o.childCell(index, &obj.aabb.Center)
+			bounds := o.looseBounds(&center, halfSize)
+			if !aabbContains(&bounds, &obj.aabb) {
+				break
+			}
+			octant := o.octant(index, &obj.aabb.Center)
+			child := o.nodes[index].children[octant]
+			if child == nullNode {
+				child = o.allocate(&center, halfSize, o.nodes[index].depth+1, index)
+				o.nodes[index].children[octant] = child
+			}
+			index = child
+		}
+	}
+
+	o.nodes[index].objects = append(o.nodes[index].objects, id)
+	obj.node = index
+	for n := index; n != nullNode; n = o.nodes[n].parent {
+		o.nodes[n].count++
+	}
+}
+
+// unlink removes the object from its node and releases the nodes that become
+// empty.
+func (o *Octree) unlink(id int) {
+	index := o.objects[id].node
+	objects := o.nodes[index].objects
+	for n := range objects {
+		if objects[n] == id {
+			objects[n] = objects[len(objects)-1]
+			o.nodes[index].objects = objects[:len(objects)-1]
+			break
+		}
+	}
+
+	for n := index; n != nullNode; {
+		node := &o.nodes[n]
+		node.count--
+		parent := node.parent
+		if node.count == 0 && parent != nullNode {
+			children := &o.nodes[parent].children
+			for k := range children {
+				if children[k] == n {
+					children[k] = nullNode
+				}
+			}
+			o.release(n)
+		}
+		n = parent
+	}
+}
+
+// octant returns the index of the child of the node whose cell contains p.
+func (o *Octree) octant(index int, p *glm.Vec3) int {
+	node := &o.nodes[index]
+	var octant int
+	for i := 0; i < 3; i++ {
+		if p[i] >= node.center[i] {
+			octant |= 1 << uint(i)
+		}
+	}
+	return octant
+}
+
+// childCell returns the cell of the child of the node whose cell contains p.
+func (o *Octree) childCell(index int, p *glm.Vec3) (center glm.Vec3, halfSize float32) {
+	node := &o.nodes[index]
+	halfSize = node.halfSize / 2
+	octant := o.octant(index, p)
+	for i := 0; i < 3; i++ {
+		if octant&(1<<uint(i)) != 0 {
+			center[i] = node.center[i] + halfSize
+		} else {
+			center[i] = node.center[i] - halfSize
+		}
+	}
+	return
+}
+
+// inCell returns true if p is in the cell of the given center and halfSize.
+func (o *Octree) inCell(center *glm.Vec3, halfSize float32, p *glm.Vec3) bool {
+	for i := 0; i < 3; i++ {
+		if p[i] < center[i]-halfSize || p[i] > center[i]+halfSize {
+			return false
+		}
+	}
+	return true
+}
+
+// looseBounds returns the bounds of the node with the given cell.
+func (o *Octree) looseBounds(center *glm.Vec3, halfSize float32) AABB {
+	e := halfSize * o.looseness
+	return AABB{Center: *center, HalfExtend: glm.Vec3{e, e, e}}
+}
+
+// allocate returns the index of a new node with the given cell.
+func (o *Octree) allocate(center *glm.Vec3, halfSize float32, depth, parent int) int {
+	index := o.free
+	if index == nullNode {
+		o.nodes = append(o.nodes, octreeNode{})
+		index = len(o.nodes) - 1
+	} else {
+		o.free = o.nodes[index].next
+	}
+	o.nodes[index] = octreeNode{
+		center:   *center,
+		halfSize: halfSize,
+		bounds:   o.looseBounds(center, halfSize),
+		depth:    depth,
+		parent:   parent,
+		children: [8]int{nullNode, nullNode, nullNode, nullNode, nullNode, nullNode, nullNode, nullNode},
+		next:     nullNode,
+	}
+	return index
+}
+
+// release puts the node back in the free list.
+func (o *Octree) release(index int) {
+	o.nodes[index] = octreeNode{next: o.free}
+	o.free = index
+}
@@ -0,0 +1,174 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkOctree verifies every object is in a node that contains it and the
+// counts of the nodes, and returns the amount of objects under index.
+func checkOctree(t *testing.T, o *Octree, index int) int {
+	node := &o.nodes[index]
+	count := len(node.objects)
+	for _, id := range node.objects {
+		if o.objects[id].node != index {
+			t.Fatalf("object %d in node %d, says %d", id, index, o.objects[id].node)
+		}
+		if index != 0 && !aabbContains(&node.bounds, &o.objects[id].aabb) {
+			t.Fatalf("object %d %v outside of node %d %v", id, o.objects[id].aabb, index, node.bounds)
+		}
+	}
+	for _, child := range node.children {
+		if child == nullNode {
+			continue
+		}
+		if o.nodes[child].parent != index || o.nodes[child].depth != node.depth+1 {
+			t.Fatalf("node %d parent = %d depth = %d, want %d and %d", child, o.nodes[child].parent, o.nodes[child].depth, index, node.depth+1)
+		}
+		n := checkOctree(t, o, child)
+		if n == 0 {
+			t.Fatalf("node %d is empty", child)
+		}
+		count += n
+	}
+	if node.count != count {
+		t.Fatalf("node %d count = %d, want %d", index, node.count, count)
+	}
+	return count
+}
+
+func TestOctree_Depth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		looseness float32
+		aabb      AABB
+		depth     int
+	}{
+		// A tight octree can only store objects that don't cross the cells.
+		{1, AABB{Center: glm.Vec3{1, 1, 1}, HalfExtend: glm.Vec3{0.5, 0.5, 0.5}}, 4},
+		{1, AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{0.1, 0.1, 0.1}}, 0},
+		{1, AABB{Center: glm.Vec3{4, 4, 4}, HalfExtend: glm.Vec3{4, 4, 4}}, 2},
+		{1, AABB{Center: glm.Vec3{8, 8, 8}, HalfExtend: glm.Vec3{5, 5, 5}}, 1},
+		{2, AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{0.1, 0.1, 0.1}}, 4},
+		{2, AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}, 4},
+		{2, AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1.5, 1.5, 1.5}}, 3},
+		{2, AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{15, 15, 15}}, 0},
+		{2, AABB{Center: glm.Vec3{30, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}, 0},
+	}
+
+	for i, test := range tests {
+		o := NewOctree(&glm.Vec3{}, 16, test.looseness, 4)
+		id := o.Insert(&test.aabb, i)
+		if depth := o.Depth(id); depth != test.depth {
+			t.Errorf("[%d] Depth = %d, want %d", i, depth, test.depth)
+		}
+		if o.Data(id) != i {
+			t.Errorf("[%d] Data = %v, want %d", i, o.Data(id), i)
+		}
+		checkOctree(t, o, 0)
+		o.Remove(id)
+		if len(o.nodes[0].objects) != 0 || o.nodes[0].count != 0 || o.nodes[0].children != [8]int{-1, -1, -1, -1, -1, -1, -1, -1} {
+			t.Errorf("[%d] root not empty after Remove", i)
+		}
+	}
+}
+
+func TestOctree_BruteForce(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	o := NewOctree(&glm.Vec3{}, 40, 2, 6)
+	aabbs := make(map[int]AABB)
+	for n := 0; n < 1000; n++ {
+		aabb := randomAABB(r)
+		aabbs[o.Insert(&aabb, nil)] = aabb
+	}
+	for id, aabb := range aabbs {
+		switch r.Intn(4) {
+		case 0:
+			o.Remove(id)
+			delete(aabbs, id)
+		case 1, 2:
+			aabb.Center.AddWith(&glm.Vec3{r.Float32()*4 - 2, r.Float32()*4 - 2, r.Float32()*4 - 2})
+			o.Update(id, &aabb)
+			aabbs[id] = aabb
+		}
+	}
+	checkOctree(t, o, 0)
+
+	for n := 0; n < 100; n++ {
+		query := randomAABB(r)
+		query.HalfExtend.MulWith(3)
+		s := Sphere{Center: glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}, Radius: r.Float32() * 10}
+		ray := Ray{
+			Origin:    glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50},
+			Direction: glm.Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1},
+		}
+
+		var wantAABB, wantSphere, wantRay []int
+		for id, aabb := range aabbs {
+			if TestAABBAABB(&aabb, &query) {
+				wantAABB = append(wantAABB, id)
+			}
+			if TestSphereAABB(&s, &aabb) {
+				wantSphere = append(wantSphere, id)
+			}
+			if _, ok := RaycastAABB(&ray, &aabb, 50); ok {
+				wantRay = append(wantRay, id)
+			}
+		}
+		sort.Ints(wantAABB)
+		sort.Ints(wantSphere)
+		sort.Ints(wantRay)
+
+		var gotAABB, gotSphere, gotRay []int
+		o.QueryAABB(&query, func(id int) bool {
+			gotAABB = append(gotAABB, id)
+			return true
+		})
+		o.QuerySphere(&s, func(id int) bool {
+			gotSphere = append(gotSphere, id)
+			return true
+		})
+		o.QueryRay(&ray, 50, func(id int, maxT float32) float32 {
+			gotRay = append(gotRay, id)
+			return maxT
+		})
+		sort.Ints(gotAABB)
+		sort.Ints(gotSphere)
+		sort.Ints(gotRay)
+		if !equalInts(gotAABB, wantAABB) {
+			t.Errorf("[%d] QueryAABB = %v, want %v", n, gotAABB, wantAABB)
+		}
+		if !equalInts(gotSphere, wantSphere) {
+			t.Errorf("[%d] QuerySphere = %v, want %v", n, gotSphere, wantSphere)
+		}
+		if !equalInts(gotRay, wantRay) {
+			t.Errorf("[%d] QueryRay = %v, want %v", n, gotRay, wantRay)
+		}
+	}
+
+	for id := range aabbs {
+		o.Remove(id)
+	}
+	checkOctree(t, o, 0)
+	if o.nodes[0].count != 0 {
+		t.Errorf("root count = %d after removing everything", o.nodes[0].count)
+	}
+}
+
+func BenchmarkOctree_QueryAABB(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	o := NewOctree(&glm.Vec3{}, 64, 2, 8)
+	for n := 0; n < 10000; n++ {
+		aabb := randomAABB(r)
+		o.Insert(&aabb, nil)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := randomAABB(r)
+		o.QueryAABB(&query, func(id int) bool { return true })
+	}
+}
@@ -1,192 +1,268 @@
 package geo
 
 import (
-	"fmt"
+	"errors"
 	"github.com/engoengine/glm"
 	"github.com/engoengine/glm/geo/internal/qhull"
-	"github.com/EngoEngine/math"
 )
 
-// Quickhull returns the convex hull of the given points.
-func Quickhull(points []glm.Vec3) {
-	// TODO(hydroflame): finish implementation
+var (
+	// ErrTooFewPoints is returned by Quickhull when there are less than 4
+	// points to build a hull from.
+	ErrTooFewPoints = errors.New("geo: a convex hull needs at least 4 points")
 
-	// 1 Find Initial tetrahedron
-	// 1.1 Find Initial Triangle
-	extremumIndices, extremums := qhull.FindExtremums(points)
+	// ErrCoincidentPoints is returned by Quickhull when all the points are
+	// the same.
+	ErrCoincidentPoints = errors.New("geo: all the points are coincident")
 
-	// 0.2 calculate the epsilon
-	epsilon := qhull.CalculateEpsilon(extremums)
+	// ErrCollinearPoints is returned by Quickhull when all the points lie on
+	// a line.
+	ErrCollinearPoints = errors.New("geo: all the points are collinear")
 
-	// 1.2 Find the 3 most extreme points
-	var triangleIndices [3]int
-	var maxArea float32
-	for i := 0; i < len(extremums); i++ {
-		for j := i + 1; j < len(extremums); j++ {
-			for k := j + 1; k < len(extremums); k++ {
-				l1, l2, l3 := extremums[i].Sub(&extremums[j]), extremums[i].Sub(&extremums[k]), extremums[j].Sub(&extremums[k])
-				area := TriangleAreaFromLengths(l1.Len(), l2.Len(), l3.Len())
-				if area > maxArea {
-					maxArea = area
-					triangleIndices = [3]int{i, j, k}
-				}
-			}
-		}
-	}
+	// ErrCoplanarPoints is returned by Quickhull when all the points lie on a
+	// plane.
+	ErrCoplanarPoints = errors.New("geo: all the points are coplanar")
 
-	// 1.3 Finish the tetrahedron
-
-	// find the last point
-	l1, l2 := extremums[triangleIndices[1]].Sub(&extremums[triangleIndices[0]]), extremums[triangleIndices[2]].Sub(&extremums[triangleIndices[0]])
-	dir := l1.Cross(&l2)
+	// ErrInvalidHorizon is returned by Quickhull when numerical errors
+	// prevented it from building a valid hull.
+	ErrInvalidHorizon = errors.New("geo: numerical error while building the convex hull")
+)
 
-	imin, imax := ExtremePointsAlongDirection(&dir, points)
-	vmin, vmax := points[imin].Sub(&extremums[triangleIndices[0]]), points[imax].Sub(&extremums[triangleIndices[0]])
-	p1, p2 := math.Abs(vmin.Dot(&dir)), math.Abs(vmax.Dot(&dir))
+// Hull is a convex polyhedron made of triangles and stored as a half-edge
+// structure.
+type Hull struct {
+	// Vertices are the positions of the vertices of the hull.
+	Vertices []glm.Vec3
 
-	var tetraIndex int
-	if p1 > p2 {
-		tetraIndex = imin
-	} else {
-		tetraIndex = imax
-	}
+	// Faces are the triangles of the hull.
+	Faces []HullFace
 
-	faces := qhull.BuildInitialTetrahedron(tetraIndex, extremumIndices[triangleIndices[0]], extremumIndices[triangleIndices[1]], extremumIndices[triangleIndices[2]], points)
+	// Edges are the half-edges of the hull, every face owns 3 of them.
+	Edges []HullEdge
+}
 
-	for _, face := range faces {
-		for n := range points {
-			if dist := DistToTriangle(&points[n], &points[face.Vertices[0]], &points[face.Vertices[1]], &points[face.Vertices[2]]); dist > epsilon {
-				face.Conflicts = append(face.Conflicts, qhull.Conflict{Distance: dist, Index: n})
-			}
-		}
-	}
+// HullFace is a triangle of a Hull.
+type HullFace struct {
+	// Vertices are the indices of the vertices of the face, ordered CCW when
+	// looking at the face from outside the hull.
+	Vertices [3]int
 
-	{ // debug
-		fmt.Println("debugging initial tetrahedron")
-		for _, face := range faces {
-			fmt.Println("face:      ", fmt.Sprintf("%p", face), points[face.Vertices[0]], points[face.Vertices[1]], points[face.Vertices[2]])
-			fmt.Println("edges:     ", face.Edges)
-			fmt.Println("conflicts: ", face.Conflicts)
-			fmt.Println()
-		}
-	}
+	// Edges are the indices of the half-edges of the face, Edges[i] starts at
+	// Vertices[i].
+	Edges [3]int
 
-	var cnt int
-	for iface, iconflict := qhull.NextConflict(faces); iface != -1; iface, iconflict = qhull.NextConflict(faces) {
-		fmt.Println("conflict", points[faces[iface].Conflicts[iconflict].Index])
+	// Normal is the outward unit normal of the face.
+	Normal glm.Vec3
+}
 
-		qhull.CleanVisited(faces)
-		for _, face := range faces {
-			fmt.Printf("%p %t\n", face, face.Visited)
-		}
+// HullEdge is a half-edge of a Hull.
+type HullEdge struct {
+	// Tail is the index of the vertex the half-edge starts at.
+	Tail int
 
-		horizon := qhull.FindHorizon(faces[iface], &points[faces[iface].Conflicts[iconflict].Index])
-		fmt.Println("horizon: ", horizon)
-		newfaces := make([]qhull.Face, len(horizon), len(horizon))
+	// Face is the index of the face the half-edge belongs to.
+	Face int
 
-		for n, edge := range horizon {
-			newfaces[n].Vertices = [3]int{faces[iface].Conflicts[iconflict].Index, edge.Tail, edge.Twin.Tail}
-			newfaces[n].Edges = [3]*qhull.Edge{
-				{Tail: newfaces[n].Vertices[1], Face: &newfaces[n]},
-				{Tail: newfaces[n].Vertices[0], Face: &newfaces[n], Twin: edge},
-				{Tail: newfaces[n].Vertices[2], Face: &newfaces[n]},
-			}
-			edge.Twin = newfaces[n].Edges[1]
+	// Next and Prev are the indices of the next and previous half-edges
+	// around Face. Twin is the index of the opposite half-edge, it belongs to
+	// the neighbouring face.
+	Next, Prev, Twin int
+}
 
-			newfaces[n].Edges[0].Next = newfaces[n].Edges[1]
-			newfaces[n].Edges[1].Next = newfaces[n].Edges[2]
-			newfaces[n].Edges[2].Next = newfaces[n].Edges[0]
+// Quickhull returns the convex hull of the given points. It returns an error
+// if the points do not span a volume.
+//
+// Points closer to the hull than a tolerance relative to the extent of the
+// point cloud are considered on the hull and may end up slightly outside of
+// it.
+func Quickhull(points []glm.Vec3) (Hull, error) {
+	if len(points) < 4 {
+		return Hull{}, ErrTooFewPoints
+	}
 
-			newfaces[n].Edges[2].Prev = newfaces[n].Edges[1]
-			newfaces[n].Edges[0].Prev = newfaces[n].Edges[2]
-			newfaces[n].Edges[1].Prev = newfaces[n].Edges[0]
+	// 0 calculate the epsilon
+	extremumIndices, extremums := qhull.FindExtremums(points)
+	epsilon := qhull.CalculateEpsilon(extremums)
 
-			newfaces[n].Faces = [3]*qhull.Face{nil, edge.Twin.Face, nil}
-			l1, l2 := points[newfaces[n].Vertices[1]].Sub(&points[newfaces[n].Vertices[0]]), points[newfaces[n].Vertices[2]].Sub(&points[newfaces[n].Vertices[0]])
-			newfaces[n].Normal = l2.Cross(&l1)
-			newfaces[n].Point = points[newfaces[n].Vertices[0]]
+	// 1 Find Initial tetrahedron
+	a, b, c, d, err := initialTetrahedron(points, extremumIndices, epsilon)
+	if err != nil {
+		return Hull{}, err
+	}
+	faces := qhull.BuildInitialTetrahedron(a, b, c, d, points)
 
-			for _, c := range faces[iface].Conflicts {
-				if dist := DistToTriangle(&points[c.Index], &points[newfaces[n].Vertices[0]], &points[newfaces[n].Vertices[1]], &points[newfaces[n].Vertices[2]]); dist > epsilon {
-					newfaces[n].Conflicts = append(newfaces[n].Conflicts, qhull.Conflict{Distance: dist, Index: c.Index})
-				}
-			}
+	// 2 Assign every point outside the tetrahedron to a face
+	for n := range points {
+		assignConflict(faces, n, points, epsilon)
+	}
 
-			for _, c := range faces[iface].Conflicts {
-				if dist := DistToTriangle(&points[c.Index], &points[newfaces[n].Vertices[0]], &points[newfaces[n].Vertices[1]], &points[newfaces[n].Vertices[2]]); dist > epsilon {
-					newfaces[n].Conflicts = append(newfaces[n].Conflicts, qhull.Conflict{Distance: dist, Index: c.Index})
-				}
-			}
+	// 3 Add the furthest conflict to the hull until there are none left
+	for iface, iconflict := qhull.NextConflict(faces); iface != -1; iface, iconflict = qhull.NextConflict(faces) {
+		eye := faces[iface].Conflicts[iconflict].Index
 
-			// check surrounding faces in case we suddently see something new
-			for _, face := range faces[iface].Faces {
-				for _, c := range face.Conflicts {
-					if dist := DistToTriangle(&points[c.Index], &points[newfaces[n].Vertices[0]], &points[newfaces[n].Vertices[1]], &points[newfaces[n].Vertices[2]]); dist > epsilon {
-						newfaces[n].Conflicts = append(newfaces[n].Conflicts, qhull.Conflict{Distance: dist, Index: c.Index})
-					}
-				}
+		qhull.CleanVisited(faces)
+		horizon := qhull.FindHorizon(faces[iface], &points[eye])
+		for n := range horizon {
+			if horizon[n].Next.Tail != horizon[(n+1)%len(horizon)].Tail {
+				return Hull{}, ErrInvalidHorizon
 			}
-
 		}
 
+		// Build a cone of faces from the horizon to the eye.
+		newfaces := make([]*qhull.Face, len(horizon))
+		for n, edge := range horizon {
+			newfaces[n] = qhull.NewFace(edge.Tail, edge.Next.Tail, eye, points)
+			qhull.Link(newfaces[n].Edges[0], edge.Twin)
+		}
 		for n := range newfaces {
-			newfaces[n].Edges[0].Twin = newfaces[(n-1+len(newfaces))%len(newfaces)].Edges[2]
-			newfaces[n].Edges[2].Twin = newfaces[(n+1)%len(newfaces)].Edges[1]
-			newfaces[n].Faces[0] = &newfaces[(n-1+len(newfaces))%len(newfaces)]
-			newfaces[n].Faces[2] = &newfaces[(n+1)%len(newfaces)]
-
-			faces = append(faces, &newfaces[n])
+			qhull.Link(newfaces[n].Edges[1], newfaces[(n+1)%len(newfaces)].Edges[2])
 		}
 
-		//clean old faces
-		var oldfaces []*qhull.Face
-		for _, e := range horizon {
-			var found bool
-			for _, face := range oldfaces {
-				if e.Face == face {
-					found = true
-					break
+		// Remove the visible faces and give their conflicts to the new faces.
+		var kept int
+		for _, face := range faces {
+			if !face.Visited {
+				faces[kept] = face
+				kept++
+				continue
+			}
+			for _, conflict := range face.Conflicts {
+				if conflict.Index != eye {
+					assignConflict(newfaces, conflict.Index, points, epsilon)
 				}
 			}
+		}
+		faces = append(faces[:kept], newfaces...)
+	}
 
-			if !found {
-				oldfaces = append(oldfaces, e.Face)
+	return buildHull(faces, points), nil
+}
+
+// initialTetrahedron returns the indices of 4 points that form a tetrahedron
+// with a volume as big as cheaply possible. d is on the inner side of {a b c}.
+func initialTetrahedron(points []glm.Vec3, extremumIndices [6]int, epsilon float32) (a, b, c, d int, err error) {
+	// Find the 2 extremums that are the furthest apart
+	var maxDist float32
+	for i := 0; i < len(extremumIndices); i++ {
+		for j := i + 1; j < len(extremumIndices); j++ {
+			v := points[extremumIndices[i]].Sub(&points[extremumIndices[j]])
+			if dist := v.Len2(); dist > maxDist {
+				maxDist = dist
+				a, b = extremumIndices[i], extremumIndices[j]
 			}
 		}
+	}
+	if maxDist <= epsilon*epsilon {
+		err = ErrCoincidentPoints
+		return
+	}
 
-		for _, face := range oldfaces {
-			for n := 0; n < len(faces); n++ {
-				if face == faces[n] {
-					faces = faces[:n+copy(faces[n:], faces[n+1:])]
-					n--
-				}
-			}
+	// Find the point furthest from the line ab
+	ab := points[b].Sub(&points[a])
+	ab.Normalize()
+	maxDist = 0
+	for n := range points {
+		ap := points[n].Sub(&points[a])
+		cross := ab.Cross(&ap)
+		if dist := cross.Len2(); dist > maxDist {
+			maxDist = dist
+			c = n
 		}
+	}
+	if maxDist <= epsilon*epsilon {
+		err = ErrCollinearPoints
+		return
+	}
 
-		{ // debug
-			for _, face := range faces {
-				fmt.Println("face:      ", fmt.Sprintf("%p", face), points[face.Vertices[0]], points[face.Vertices[1]], points[face.Vertices[2]])
-				fmt.Println("edges:     ", face.Edges)
-				fmt.Println("conflicts: ", face.Conflicts)
-				fmt.Println()
-			}
+	// Find the point furthest from the plane abc
+	plane := qhull.NewFace(a, b, c, points)
+	maxDist = 0
+	var signedDist float32
+	for n := range points {
+		dist := plane.Distance(&points[n])
+		if dist*dist > maxDist {
+			maxDist = dist * dist
+			signedDist = dist
+			d = n
 		}
+	}
+	if maxDist <= epsilon*epsilon {
+		err = ErrCoplanarPoints
+		return
+	}
 
-		if cnt >= 1 {
-			break
+	// Flip the base triangle if d is in front of it.
+	if signedDist > 0 {
+		b, c = c, b
+	}
+	return
+}
+
+// assignConflict adds point n to the conflict list of the face it is the
+// furthest in front of, if any.
+func assignConflict(faces []*qhull.Face, n int, points []glm.Vec3, epsilon float32) {
+	var maxDist = epsilon
+	var best *qhull.Face
+	for _, face := range faces {
+		if dist := face.Distance(&points[n]); dist > maxDist {
+			maxDist = dist
+			best = face
 		}
-		cnt++
+	}
+	if best != nil {
+		best.Conflicts = append(best.Conflicts, qhull.Conflict{Distance: maxDist, Index: n})
+	}
+}
 
+// buildHull turns the quickhull faces into a Hull containing only the vertices
+// used by the faces.
+func buildHull(faces []*qhull.Face, points []glm.Vec3) Hull {
+	var hull Hull
+	hull.Faces = make([]HullFace, len(faces))
+	hull.Edges = make([]HullEdge, 3*len(faces))
+
+	// The quickhull faces are ordered CW when seen from outside. Every output
+	// half-edge is the reverse of an internal one.
+	edgeIndices := make(map[*qhull.Edge]int, 3*len(faces))
+	for n, face := range faces {
+		edgeIndices[face.Edges[2]] = 3*n + 0
+		edgeIndices[face.Edges[1]] = 3*n + 1
+		edgeIndices[face.Edges[0]] = 3*n + 2
 	}
 
-	/*for _, face := range faces {
-		fmt.Printf("v %f %f %f\n", points[face.Vertices[0]][0], points[face.Vertices[0]][1], points[face.Vertices[0]][2])
-		fmt.Printf("v %f %f %f\n", points[face.Vertices[1]][0], points[face.Vertices[1]][1], points[face.Vertices[1]][2])
-		fmt.Printf("v %f %f %f\n", points[face.Vertices[2]][0], points[face.Vertices[2]][1], points[face.Vertices[2]][2])
+	vertexIndices := make(map[int]int)
+	for n, face := range faces {
+		hf := &hull.Faces[n]
+		hf.Normal = face.Normal
+		for m, v := range [3]int{face.Vertices[0], face.Vertices[2], face.Vertices[1]} {
+			index, ok := vertexIndices[v]
+			if !ok {
+				index = len(hull.Vertices)
+				vertexIndices[v] = index
+				hull.Vertices = append(hull.Vertices, points[v])
+			}
+			hf.Vertices[m] = index
+			hf.Edges[m] = 3*n + m
+		}
 	}
 
-	for n := range faces {
-		fmt.Printf("f %d %d %d\n", (n)*3+3, (n)*3+2, (n)*3+1)
-	}*/
+	for n, face := range faces {
+		for _, e := range face.Edges {
+			i := edgeIndices[e]
+			hull.Edges[i] = HullEdge{
+				Tail: hull.Faces[n].Vertices[i-3*n],
+				Face: n,
+				Next: 3*n + (i-3*n+1)%3,
+				Prev: 3*n + (i-3*n+2)%3,
+				Twin: edgeIndices[e.Twin],
+			}
+		}
+	}
+	return hull
+}
 
+// Support returns the vertex of the hull furthest along direction.
+func (h *Hull) Support(direction glm.Vec3) glm.Vec3 {
+	_, imax := ExtremePointsAlongDirection(&direction, h.Vertices)
+	return h.Vertices[imax]
 }
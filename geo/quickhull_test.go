@@ -2,14 +2,134 @@ package geo
 
 import (
 	"github.com/engoengine/glm"
+	"math/rand"
 	"testing"
 )
 
+// checkHull verifies the topology of the hull and that every point is inside
+// it. The points used in the tests are within [-2, 2] so the tolerance used by
+// Quickhull is at most 0.0018.
+func checkHull(t *testing.T, i int, hull *Hull, points []glm.Vec3) {
+	if v, e, f := len(hull.Vertices), len(hull.Edges)/2, len(hull.Faces); v-e+f != 2 {
+		t.Errorf("[%d] V - E + F = %d - %d + %d, want 2", i, v, e, f)
+	}
+
+	for n, edge := range hull.Edges {
+		twin := hull.Edges[edge.Twin]
+		if twin.Twin != n {
+			t.Errorf("[%d] edge %d twin of twin = %d", i, n, twin.Twin)
+		}
+		if next := hull.Edges[edge.Next]; twin.Tail != next.Tail {
+			t.Errorf("[%d] edge %d twin tail = %d, want %d", i, n, twin.Tail, next.Tail)
+		}
+		if hull.Edges[edge.Next].Prev != n {
+			t.Errorf("[%d] edge %d next.prev = %d", i, n, hull.Edges[edge.Next].Prev)
+		}
+	}
+
+	for n, face := range hull.Faces {
+		a, b, c := &hull.Vertices[face.Vertices[0]], &hull.Vertices[face.Vertices[1]], &hull.Vertices[face.Vertices[2]]
+		plane := PlaneFromPoints(a, b, c)
+		if !plane.N.EqualThreshold(&face.Normal, 1e-4) {
+			t.Errorf("[%d] face %d normal = %v, want %v", i, n, face.Normal, plane.N)
+		}
+		for m := range points {
+			if d := DistanceToPlane(&plane, &points[m]); d > 2e-3 {
+				t.Errorf("[%d] point %v is %f in front of face %d", i, points[m], d, n)
+			}
+		}
+	}
+}
+
 func TestQuickhull(t *testing.T) {
-	t.Skip("implementation unfinished")
-	points := []glm.Vec3{{0, 0, 0}, {1, 1, 1},
-		{2, 0, 0}, {0, 2, 0}, {0, 0, 2},
-		{-1, 0, 0}, {0, -1, 0}, {0, 0, -1}, {0.1, 0.1, 0.1},
-		{0, 1.9, 1.9}} // that last one should see 2 faces :\
-	Quickhull(points)
+	t.Parallel()
+	tests := []struct {
+		points          []glm.Vec3
+		vertices, faces int
+	}{
+		{ // 0 tetrahedron
+			points:   []glm.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+			vertices: 4,
+			faces:    4,
+		},
+		{ // 1
+			points: []glm.Vec3{{0, 0, 0}, {1, 1, 1},
+				{2, 0, 0}, {0, 2, 0}, {0, 0, 2},
+				{-1, 0, 0}, {0, -1, 0}, {0, 0, -1}, {0.1, 0.1, 0.1},
+				{0, 1.9, 1.9}},
+			vertices: 8,
+			faces:    12,
+		},
+		{ // 2 cube with points on the faces and inside
+			points: []glm.Vec3{
+				{-1, -1, -1}, {1, -1, -1}, {-1, 1, -1}, {1, 1, -1},
+				{-1, -1, 1}, {1, -1, 1}, {-1, 1, 1}, {1, 1, 1},
+				{0, 0, 0}, {0, 0, 1}, {1, 0, 0}, {0, -1, 0}, {0.5, 0.5, 0.5},
+				{1, 1, 1},
+			},
+			vertices: 8,
+			faces:    12,
+		},
+	}
+
+	for i, test := range tests {
+		hull, err := Quickhull(test.points)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+			continue
+		}
+		if len(hull.Vertices) != test.vertices || len(hull.Faces) != test.faces {
+			t.Errorf("[%d] got %d vertices and %d faces, want %d and %d", i,
+				len(hull.Vertices), len(hull.Faces), test.vertices, test.faces)
+		}
+		checkHull(t, i, &hull, test.points)
+	}
+}
+
+func TestQuickhull_Random(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		points := make([]glm.Vec3, 10+r.Intn(200))
+		for n := range points {
+			points[n] = glm.Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1}
+		}
+		hull, err := Quickhull(points)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+			continue
+		}
+		checkHull(t, i, &hull, points)
+	}
+}
+
+func TestQuickhull_Degenerate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		points []glm.Vec3
+		err    error
+	}{
+		{
+			points: []glm.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+			err:    ErrTooFewPoints,
+		},
+		{
+			points: []glm.Vec3{{1, 2, 3}, {1, 2, 3}, {1, 2, 3}, {1, 2, 3}, {1, 2, 3}},
+			err:    ErrCoincidentPoints,
+		},
+		{
+			points: []glm.Vec3{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}, {-3, -3, -3}, {1, 1, 1}},
+			err:    ErrCollinearPoints,
+		},
+		{
+			points: []glm.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0.5, 0.2, 0}},
+			err:    ErrCoplanarPoints,
+		},
+	}
+
+	for i, test := range tests {
+		if _, err := Quickhull(test.points); err != test.err {
+			t.Errorf("[%d] error = %v, want %v", i, err, test.err)
+		}
+	}
 }
@@ -0,0 +1,302 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// Ray is the half-line R(t) = Origin + t*Direction, t >= 0. Direction doesn't
+// need to be normalized, t is then measured in multiples of its length.
+type Ray struct {
+	Origin    glm.Vec3
+	Direction glm.Vec3
+}
+
+// At returns the point of the ray at t.
+func (r *Ray) At(t float32) glm.Vec3 {
+	p := r.Origin
+	p.AddScaledVec(t, &r.Direction)
+	return p
+}
+
+// Hit is the result of a successful ray cast.
+type Hit struct {
+	// T is where the ray hit the shape, Point = Ray.At(T).
+	T float32
+
+	// Point is the position of the hit.
+	Point glm.Vec3
+
+	// Normal is the unit normal of the surface at Point. For solid shapes it
+	// points outward, for flat shapes it faces the origin of the ray. If the
+	// ray starts inside a solid shape the hit is at T = 0 and Normal is
+	// opposite to the ray direction.
+	Normal glm.Vec3
+}
+
+// insideHit returns the hit of a ray starting inside a solid shape.
+func insideHit(r *Ray) Hit {
+	h := Hit{Point: r.Origin, Normal: r.Direction.Inverse()}
+	h.Normal.Normalize()
+	return h
+}
+
+// RaycastSphere casts the ray against the sphere and returns the first hit
+// with T in [0, maxT].
+func RaycastSphere(r *Ray, s *Sphere, maxT float32) (Hit, bool) {
+	m := r.Origin.Sub(&s.Center)
+	a := r.Direction.Dot(&r.Direction)
+	b := m.Dot(&r.Direction)
+	c := m.Dot(&m) - s.Radius*s.Radius
+
+	// The ray starts inside the sphere.
+	if c <= 0 {
+		return insideHit(r), true
+	}
+	// Exit if the ray points away from the sphere or misses it.
+	discr := b*b - a*c
+	if b > 0 || discr < 0 || a == 0 {
+		return Hit{}, false
+	}
+
+	t := (-b - math.Sqrt(discr)) / a
+	if t > maxT {
+		return Hit{}, false
+	}
+	h := Hit{T: t, Point: r.At(t)}
+	h.Normal = h.Point.Sub(&s.Center)
+	h.Normal.Normalize()
+	return h, true
+}
+
+// convexCast clips a ray against the half-spaces n.x <= offset whose
+// intersection forms a convex shape.
+type convexCast struct {
+	// the interval of the ray inside every half-space so far.
+	tmin, tmax float32
+
+	// the normal of the half-space the ray enters through at tmin, zero if the
+	// ray starts inside.
+	normal glm.Vec3
+}
+
+// clip clips the ray against the half-space n.x <= offset. It returns false if
+// the ray misses the shape.
+func (c *convexCast) clip(r *Ray, n *glm.Vec3, offset float32) bool {
+	denom := n.Dot(&r.Direction)
+	dist := offset - n.Dot(&r.Origin)
+	if denom == 0 {
+		// The ray is parallel to the plane, it must start inside.
+		return dist >= 0
+	}
+
+	t := dist / denom
+	if denom < 0 {
+		if t > c.tmin {
+			c.tmin, c.normal = t, *n
+		}
+	} else if t < c.tmax {
+		c.tmax = t
+	}
+	return c.tmin <= c.tmax
+}
+
+// slab clips the ray against min <= n.x <= max.
+func (c *convexCast) slab(r *Ray, n *glm.Vec3, min, max float32) bool {
+	if !c.clip(r, n, max) {
+		return false
+	}
+	m := n.Inverse()
+	return c.clip(r, &m, -min)
+}
+
+// hit returns the hit at the entry point of the ray.
+func (c *convexCast) hit(r *Ray) Hit {
+	if c.normal == (glm.Vec3{}) {
+		return insideHit(r)
+	}
+	h := Hit{T: c.tmin, Point: r.At(c.tmin), Normal: c.normal}
+	h.Normal.Normalize()
+	return h
+}
+
+// RaycastAABB casts the ray against the AABB and returns the first hit with T
+// in [0, maxT].
+func RaycastAABB(r *Ray, a *AABB, maxT float32) (Hit, bool) {
+	c := convexCast{tmax: maxT}
+	for i := 0; i < 3; i++ {
+		var n glm.Vec3
+		n[i] = 1
+		if !c.slab(r, &n, a.Center[i]-a.HalfExtend[i], a.Center[i]+a.HalfExtend[i]) {
+			return Hit{}, false
+		}
+	}
+	return c.hit(r), true
+}
+
+// RaycastOBB casts the ray against the OBB and returns the first hit with T
+// in [0, maxT].
+func RaycastOBB(r *Ray, o *OBB, maxT float32) (Hit, bool) {
+	c := convexCast{tmax: maxT}
+	for i := 0; i < 3; i++ {
+		center := o.Orientation[i].Dot(&o.Center)
+		if !c.slab(r, &o.Orientation[i], center-o.HalfExtend[i], center+o.HalfExtend[i]) {
+			return Hit{}, false
+		}
+	}
+	return c.hit(r), true
+}
+
+// RaycastDOP8 casts the ray against the 8-DOP and returns the first hit with T
+// in [0, maxT].
+func RaycastDOP8(r *Ray, d *DOP8, maxT float32) (Hit, bool) {
+	c := convexCast{tmax: maxT}
+	for k := 0; k < 4; k++ {
+		if !c.slab(r, &dop8Axes[k], d.Min[k], d.Max[k]) {
+			return Hit{}, false
+		}
+	}
+	return c.hit(r), true
+}
+
+// RaycastHull casts the ray against the convex hull and returns the first hit
+// with T in [0, maxT].
+func RaycastHull(r *Ray, h *Hull, maxT float32) (Hit, bool) {
+	c := convexCast{tmax: maxT}
+	for n := range h.Faces {
+		face := &h.Faces[n]
+		if !c.clip(r, &face.Normal, face.Normal.Dot(&h.Vertices[face.Vertices[0]])) {
+			return Hit{}, false
+		}
+	}
+	return c.hit(r), true
+}
+
+// RaycastCapsule casts the ray against the capsule and returns the first hit
+// with T in [0, maxT].
+func RaycastCapsule(r *Ray, c *Capsule, maxT float32) (Hit, bool) {
+	if SqDistPointSegment(&c.A, &c.B, &r.Origin) <= c.Radius*c.Radius {
+		return insideHit(r), true
+	}
+
+	// The ray starts outside, the first hit is the closest of the hits with
+	// the cylinder and the 2 spheres at the ends.
+	var best Hit
+	found := false
+	for _, center := range [2]*glm.Vec3{&c.A, &c.B} {
+		if h, ok := RaycastSphere(r, &Sphere{Center: *center, Radius: c.Radius}, maxT); ok && (!found || h.T < best.T) {
+			best, found = h, true
+		}
+	}
+
+	ab, ao := c.B.Sub(&c.A), r.Origin.Sub(&c.A)
+	abab := ab.Dot(&ab)
+	if abab == 0 {
+		return best, found
+	}
+
+	// Remove the component along the axis and intersect the infinite
+	// cylinder.
+	d := r.Direction
+	d.AddScaledVec(-d.Dot(&ab)/abab, &ab)
+	m := ao
+	m.AddScaledVec(-m.Dot(&ab)/abab, &ab)
+	a, b, k := d.Dot(&d), m.Dot(&d), m.Dot(&m)-c.Radius*c.Radius
+	discr := b*b - a*k
+	if a == 0 || discr < 0 {
+		return best, found
+	}
+	t := (-b - math.Sqrt(discr)) / a
+	if t < 0 || t > maxT || (found && t >= best.T) {
+		return best, found
+	}
+	p := r.At(t)
+	ap := p.Sub(&c.A)
+	s := ap.Dot(&ab) / abab
+	if s < 0 || s > 1 {
+		return best, found
+	}
+
+	axis := c.A
+	axis.AddScaledVec(s, &ab)
+	h := Hit{T: t, Point: p, Normal: p.Sub(&axis)}
+	h.Normal.Normalize()
+	return h, true
+}
+
+// RaycastPlane casts the ray against the plane and returns the hit if T is in
+// [0, maxT].
+func RaycastPlane(r *Ray, p *Plane, maxT float32) (Hit, bool) {
+	denom := p.N.Dot(&r.Direction)
+	if denom == 0 {
+		return Hit{}, false
+	}
+	op := p.P.Sub(&r.Origin)
+	t := op.Dot(&p.N) / denom
+	if t < 0 || t > maxT {
+		return Hit{}, false
+	}
+
+	h := Hit{T: t, Point: r.At(t), Normal: p.N}
+	if denom > 0 {
+		h.Normal.Invert()
+	}
+	h.Normal.Normalize()
+	return h, true
+}
+
+// RaycastRect casts the ray against the rectangle and returns the hit if T is
+// in [0, maxT].
+func RaycastRect(r *Ray, rect *Rect, maxT float32) (Hit, bool) {
+	plane := Plane{N: rect.Orientation[0].Cross(&rect.Orientation[1]), P: rect.Center}
+	h, ok := RaycastPlane(r, &plane, maxT)
+	if !ok {
+		return Hit{}, false
+	}
+
+	d := h.Point.Sub(&rect.Center)
+	for i := 0; i < 2; i++ {
+		if math.Abs(d.Dot(&rect.Orientation[i])) > rect.HalfExtend[i] {
+			return Hit{}, false
+		}
+	}
+	return h, true
+}
+
+// RaycastTriangle casts the ray against the triangle {a b c} and returns the
+// hit if T is in [0, maxT]. Both sides of the triangle are hit.
+func RaycastTriangle(r *Ray, a, b, c *glm.Vec3, maxT float32) (Hit, bool) {
+	ab, ac := b.Sub(a), c.Sub(a)
+	pvec := r.Direction.Cross(&ac)
+	det := ab.Dot(&pvec)
+	if det == 0 {
+		// The ray is parallel to the triangle.
+		return Hit{}, false
+	}
+	invDet := 1 / det
+
+	// Compute the barycentric coordinates of the intersection with the plane
+	// of the triangle and test if they are within bounds.
+	ao := r.Origin.Sub(a)
+	u := ao.Dot(&pvec) * invDet
+	if u < 0 || u > 1 {
+		return Hit{}, false
+	}
+	qvec := ao.Cross(&ab)
+	v := r.Direction.Dot(&qvec) * invDet
+	if v < 0 || u+v > 1 {
+		return Hit{}, false
+	}
+	t := ac.Dot(&qvec) * invDet
+	if t < 0 || t > maxT {
+		return Hit{}, false
+	}
+
+	h := Hit{T: t, Point: r.At(t), Normal: ab.Cross(&ac)}
+	if det < 0 {
+		// The ray hits the back of the triangle.
+		h.Normal.Invert()
+	}
+	h.Normal.Normalize()
+	return h, true
+}
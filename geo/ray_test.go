@@ -0,0 +1,222 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestRaycast(t *testing.T) {
+	t.Parallel()
+	const sqrt2, invSqrt3 = 1.4142135, 0.57735027
+	cube := []glm.Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {-1, 1, -1}, {1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {-1, 1, 1}, {1, 1, 1},
+	}
+	hull, err := Quickhull(cube)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dop DOP8
+	DOP8FromPoints(&dop, cube)
+
+	sphere := Sphere{Center: glm.Vec3{0, 0, 0}, Radius: 1}
+	aabb := AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}
+	obb := rotatedOBB(glm.Vec3{0, 0, 0}, glm.Vec3{1, 1, 1}, math.Pi/4, glm.Vec3{0, 0, 1})
+	capsule := Capsule{A: glm.Vec3{0, -1, 0}, B: glm.Vec3{0, 1, 0}, Radius: 0.5}
+	plane := Plane{N: glm.Vec3{0, 1, 0}, P: glm.Vec3{0, 0, 0}}
+	rect := Rect{Orientation: [2]glm.Vec3{{1, 0, 0}, {0, 0, 1}}, HalfExtend: glm.Vec2{1, 1}}
+	triangle := [3]glm.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+
+	tests := []struct {
+		cast   func(r *Ray, maxT float32) (Hit, bool)
+		ray    Ray
+		maxT   float32
+		hit    bool
+		t      float32
+		normal glm.Vec3
+	}{
+		{ // 0
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastSphere(r, &sphere, maxT) },
+			ray:    Ray{Origin: glm.Vec3{-5, 0, 0}, Direction: glm.Vec3{1, 0, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      4,
+			normal: glm.Vec3{-1, 0, 0},
+		},
+		{ // 1 direction isn't normalized
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastSphere(r, &sphere, maxT) },
+			ray:    Ray{Origin: glm.Vec3{-5, 0, 0}, Direction: glm.Vec3{2, 0, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      2,
+			normal: glm.Vec3{-1, 0, 0},
+		},
+		{ // 2 starts inside
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastSphere(r, &sphere, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0.5, 0, 0}, Direction: glm.Vec3{0, 3, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      0,
+			normal: glm.Vec3{0, -1, 0},
+		},
+		{ // 3 too short
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastSphere(r, &sphere, maxT) },
+			ray:  Ray{Origin: glm.Vec3{-5, 0, 0}, Direction: glm.Vec3{1, 0, 0}},
+			maxT: 3,
+		},
+		{ // 4 points away
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastSphere(r, &sphere, maxT) },
+			ray:  Ray{Origin: glm.Vec3{-5, 0, 0}, Direction: glm.Vec3{-1, 0, 0}},
+			maxT: 10,
+		},
+		{ // 5
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastAABB(r, &aabb, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0.5, 5, 0.5}, Direction: glm.Vec3{0, -1, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      4,
+			normal: glm.Vec3{0, 1, 0},
+		},
+		{ // 6
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastAABB(r, &aabb, maxT) },
+			ray:  Ray{Origin: glm.Vec3{1.5, 5, 0.5}, Direction: glm.Vec3{0, -1, 0}},
+			maxT: 10,
+		},
+		{ // 7
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastAABB(r, &aabb, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0, 0, 0}, Direction: glm.Vec3{0, 0, -1}},
+			maxT:   10,
+			hit:    true,
+			t:      0,
+			normal: glm.Vec3{0, 0, 1},
+		},
+		{ // 8
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastOBB(r, &obb, maxT) },
+			ray:    Ray{Origin: glm.Vec3{5, 5, 0}, Direction: glm.Vec3{-1 / sqrt2, -1 / sqrt2, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      5*sqrt2 - 1,
+			normal: glm.Vec3{1 / sqrt2, 1 / sqrt2, 0},
+		},
+		{ // 9
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastOBB(r, &obb, maxT) },
+			ray:  Ray{Origin: glm.Vec3{5, 0, 1.1}, Direction: glm.Vec3{-1, 0, 0}},
+			maxT: 10,
+		},
+		{ // 10
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastCapsule(r, &capsule, maxT) },
+			ray:    Ray{Origin: glm.Vec3{5, 0.5, 0}, Direction: glm.Vec3{-1, 0, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      4.5,
+			normal: glm.Vec3{1, 0, 0},
+		},
+		{ // 11 hits an end
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastCapsule(r, &capsule, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0, 5, 0}, Direction: glm.Vec3{0, -1, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      3.5,
+			normal: glm.Vec3{0, 1, 0},
+		},
+		{ // 12
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastCapsule(r, &capsule, maxT) },
+			ray:  Ray{Origin: glm.Vec3{5, 0, 0.6}, Direction: glm.Vec3{-1, 0, 0}},
+			maxT: 10,
+		},
+		{ // 13
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastPlane(r, &plane, maxT) },
+			ray:    Ray{Origin: glm.Vec3{1, 3, 2}, Direction: glm.Vec3{0, -1, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      3,
+			normal: glm.Vec3{0, 1, 0},
+		},
+		{ // 14 from below
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastPlane(r, &plane, maxT) },
+			ray:    Ray{Origin: glm.Vec3{1, -3, 2}, Direction: glm.Vec3{0, 1, 1}},
+			maxT:   10,
+			hit:    true,
+			t:      3,
+			normal: glm.Vec3{0, -1, 0},
+		},
+		{ // 15
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastRect(r, &rect, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0.5, 2, 0.5}, Direction: glm.Vec3{0, -1, 0}},
+			maxT:   10,
+			hit:    true,
+			t:      2,
+			normal: glm.Vec3{0, 1, 0},
+		},
+		{ // 16
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastRect(r, &rect, maxT) },
+			ray:  Ray{Origin: glm.Vec3{2, 2, 0}, Direction: glm.Vec3{0, -1, 0}},
+			maxT: 10,
+		},
+		{ // 17
+			cast: func(r *Ray, maxT float32) (Hit, bool) {
+				return RaycastTriangle(r, &triangle[0], &triangle[1], &triangle[2], maxT)
+			},
+			ray:    Ray{Origin: glm.Vec3{0.2, 0.2, 1}, Direction: glm.Vec3{0, 0, -1}},
+			maxT:   10,
+			hit:    true,
+			t:      1,
+			normal: glm.Vec3{0, 0, 1},
+		},
+		{ // 18 back side
+			cast: func(r *Ray, maxT float32) (Hit, bool) {
+				return RaycastTriangle(r, &triangle[0], &triangle[1], &triangle[2], maxT)
+			},
+			ray:    Ray{Origin: glm.Vec3{0.2, 0.2, -1}, Direction: glm.Vec3{0, 0, 1}},
+			maxT:   10,
+			hit:    true,
+			t:      1,
+			normal: glm.Vec3{0, 0, -1},
+		},
+		{ // 19
+			cast: func(r *Ray, maxT float32) (Hit, bool) {
+				return RaycastTriangle(r, &triangle[0], &triangle[1], &triangle[2], maxT)
+			},
+			ray:  Ray{Origin: glm.Vec3{0.6, 0.6, 1}, Direction: glm.Vec3{0, 0, -1}},
+			maxT: 10,
+		},
+		{ // 20
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastDOP8(r, &dop, maxT) },
+			ray:    Ray{Origin: glm.Vec3{5, 5, 5}, Direction: glm.Vec3{-1, -1, -1}},
+			maxT:   10,
+			hit:    true,
+			t:      4,
+			normal: glm.Vec3{invSqrt3, invSqrt3, invSqrt3},
+		},
+		{ // 21
+			cast:   func(r *Ray, maxT float32) (Hit, bool) { return RaycastHull(r, &hull, maxT) },
+			ray:    Ray{Origin: glm.Vec3{0.5, 0.2, 5}, Direction: glm.Vec3{0, 0, -1}},
+			maxT:   10,
+			hit:    true,
+			t:      4,
+			normal: glm.Vec3{0, 0, 1},
+		},
+		{ // 22
+			cast: func(r *Ray, maxT float32) (Hit, bool) { return RaycastHull(r, &hull, maxT) },
+			ray:  Ray{Origin: glm.Vec3{0.5, 0.2, 5}, Direction: glm.Vec3{0, 0, 1}},
+			maxT: 10,
+		},
+	}
+
+	for i, test := range tests {
+		h, ok := test.cast(&test.ray, test.maxT)
+		if ok != test.hit {
+			t.Errorf("[%d] hit = %t, want %t", i, ok, test.hit)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		point := test.ray.At(test.t)
+		dp, dn := h.Point.Sub(&point), h.Normal.Sub(&test.normal)
+		if math.Abs(h.T-test.t) > 1e-4 || dp.Len() > 1e-4 || dn.Len() > 1e-4 {
+			t.Errorf("[%d] hit = %+v, want t = %f, point = %v, normal = %v", i, h, test.t, point, test.normal)
+		}
+	}
+}
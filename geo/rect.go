@@ -61,3 +61,16 @@ func SqDistRectPoint(r *Rect, p *glm.Vec3) float32 {
 	}
 	return sqDist
 }
+
+// Support returns the point of the rectangle furthest along direction.
+func (r *Rect) Support(direction glm.Vec3) glm.Vec3 {
+	p := r.Center
+	for i := 0; i < 2; i++ {
+		if direction.Dot(&r.Orientation[i]) < 0 {
+			p.AddScaledVec(-r.HalfExtend[i], &r.Orientation[i])
+		} else {
+			p.AddScaledVec(r.HalfExtend[i], &r.Orientation[i])
+		}
+	}
+	return p
+}
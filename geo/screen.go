@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// ScreenRay returns the ray going through the window coordinates x, y, like
+// the ones of a mouse cursor, for the camera with the given view and
+// projection matrices. viewport is the x, y, width and height of the window,
+// like for glm.Project. The ray starts on the near plane and its direction is
+// normalized, maxT is the distance from the near plane to the far plane along
+// the ray. A projection without far plane, like glm.PerspectiveInfinite, gives
+// an unbounded ray and maxT is math.Inf(1). It works for perspective and
+// orthographic projections. It returns false if view*proj isn't invertible.
+func ScreenRay(x, y float32, view, proj *glm.Mat4, viewport [4]int) (r Ray, maxT float32, ok bool) {
+	return ScreenRayDepth(x, y, view, proj, viewport, glm.DepthNegativeOneToOne)
+}
+
+// ScreenRayDepth is like ScreenRay for a projection using the depth range
+// depth.
+func ScreenRayDepth(x, y float32, view, proj *glm.Mat4, viewport [4]int, depth glm.DepthRange) (r Ray, maxT float32, ok bool) {
+	nearDepth, farDepth := depth.NDC()
+
+	m := proj.Mul4(view)
+	inv := m.Inverse()
+	if inv == (glm.Mat4{}) {
+		return Ray{}, 0, false
+	}
+
+	// The point halfway between the near and far planes is never at infinity,
+	// even without far plane, it gives the direction.
+	ndcX := 2*(x-float32(viewport[0]))/float32(viewport[2]) - 1
+	ndcY := 2*(y-float32(viewport[1]))/float32(viewport[3]) - 1
+	near := inv.Mul4x1(&glm.Vec4{ndcX, ndcY, nearDepth, 1})
+	mid := inv.Mul4x1(&glm.Vec4{ndcX, ndcY, (nearDepth + farDepth) / 2, 1})
+	if near[3] == 0 || mid[3] == 0 {
+		return Ray{}, 0, false
+	}
+
+	r.Origin = glm.Vec3{near[0] / near[3], near[1] / near[3], near[2] / near[3]}
+	r.Direction = glm.Vec3{mid[0] / mid[3], mid[1] / mid[3], mid[2] / mid[3]}
+	r.Direction.SubWith(&r.Origin)
+	if r.Direction.Len2() == 0 {
+		return Ray{}, 0, false
+	}
+	r.Direction.Normalize()
+
+	// Without far plane the far point is at infinity, w is 0 or a rounding
+	// error that can put the point behind the near plane.
+	maxT = math.Inf(1)
+	if far := inv.Mul4x1(&glm.Vec4{ndcX, ndcY, farDepth, 1}); far[3] != 0 {
+		p := glm.Vec3{far[0] / far[3], far[1] / far[3], far[2] / far[3]}
+		p.SubWith(&r.Origin)
+		if t := p.Dot(&r.Direction); t > 0 && !math.IsInf(t, 1) {
+			maxT = t
+		}
+	}
+	return r, maxT, true
+}
@@ -0,0 +1,80 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestScreenRay(t *testing.T) {
+	t.Parallel()
+	view := glm.LookAtV(&glm.Vec3{0, 0, 10}, &glm.Vec3{0, 0, 0}, &glm.Vec3{0, 1, 0})
+	perspective := glm.Perspective(math.Pi/2, 2, 1, 100)
+	ortho := glm.Ortho(-4, 4, -2, 2, 1, 100)
+	viewport := [4]int{100, 50, 800, 400}
+	s2 := math.Sqrt(2) / 2
+
+	tests := []struct {
+		x, y   float32
+		proj   *glm.Mat4
+		origin glm.Vec3
+		dir    glm.Vec3
+		maxT   float32
+	}{
+		{500, 250, &perspective, glm.Vec3{0, 0, 9}, glm.Vec3{0, 0, -1}, 99},
+		{900, 250, &perspective, glm.Vec3{2, 0, 9}, glm.Vec3{2 / math.Sqrt(5), 0, -1 / math.Sqrt(5)}, 99 * math.Sqrt(5)},
+		{500, 450, &perspective, glm.Vec3{0, 1, 9}, glm.Vec3{0, s2, -s2}, 99 * math.Sqrt(2)},
+		{500, 250, &ortho, glm.Vec3{0, 0, 9}, glm.Vec3{0, 0, -1}, 99},
+		{100, 450, &ortho, glm.Vec3{-4, 2, 9}, glm.Vec3{0, 0, -1}, 99},
+	}
+
+	for i, test := range tests {
+		r, maxT, ok := ScreenRay(test.x, test.y, &view, test.proj, viewport)
+		if !ok {
+			t.Errorf("[%d] ScreenRay failed", i)
+			continue
+		}
+		if d := r.Origin.Sub(&test.origin); d.Len() > 1e-4 {
+			t.Errorf("[%d] origin = %v, want %v", i, r.Origin, test.origin)
+		}
+		if d := r.Direction.Sub(&test.dir); d.Len() > 1e-4 {
+			t.Errorf("[%d] direction = %v, want %v", i, r.Direction, test.dir)
+		}
+		if math.Abs(maxT-test.maxT) > 1e-2 {
+			t.Errorf("[%d] maxT = %f, want %f", i, maxT, test.maxT)
+		}
+	}
+}
+
+func TestScreenRayDepth(t *testing.T) {
+	t.Parallel()
+	view := glm.LookAtV(&glm.Vec3{1, 2, 10}, &glm.Vec3{0, 0, 0}, &glm.Vec3{0, 1, 0})
+	viewport := [4]int{0, 0, 640, 480}
+	gl := glm.Perspective(1, 4.0/3.0, 0.5, 50)
+	want, wantMaxT, _ := ScreenRay(200, 300, &view, &gl, viewport)
+
+	for i, depth := range []glm.DepthRange{glm.DepthNegativeOneToOne, glm.DepthZeroToOne, glm.DepthReverseZ} {
+		for j, proj := range []glm.Mat4{
+			glm.PerspectiveDepth(1, 4.0/3.0, 0.5, 50, depth),
+			glm.PerspectiveInfinite(1, 4.0/3.0, 0.5, depth),
+		} {
+			r, maxT, ok := ScreenRayDepth(200, 300, &view, &proj, viewport, depth)
+			if !ok || !r.Origin.EqualThreshold(&want.Origin, 1e-3) || !r.Direction.EqualThreshold(&want.Direction, 1e-3) {
+				t.Errorf("[%d][%d] ScreenRayDepth = %v %t, want %v", i, j, r, ok, want)
+			}
+			// The rays of the infinite projection are unbounded.
+			if expected := []float32{wantMaxT, math.Inf(1)}[j]; !glm.FloatEqualThreshold(maxT, expected, 1e-3) {
+				t.Errorf("[%d][%d] maxT = %f, want %f", i, j, maxT, expected)
+			}
+		}
+	}
+}
+
+func TestScreenRay_Singular(t *testing.T) {
+	t.Parallel()
+	view := glm.Ident4()
+	var proj glm.Mat4
+	if r, _, ok := ScreenRay(0, 0, &view, &proj, [4]int{0, 0, 640, 480}); ok {
+		t.Errorf("ScreenRay with a singular matrix = %v", r)
+	}
+}
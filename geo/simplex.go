@@ -1,7 +1,6 @@
 package geo
 
 import (
-	"fmt"
 	"github.com/engoengine/glm"
 	"strconv"
 )
@@ -12,6 +11,16 @@ type Simplex struct {
 	// the Points contained in the simplex. Data past Points[Size] is assumed to
 	// be garbage.
 	Points [4]glm.Vec3 // use an array to keep the memory all in 1 spot
+
+	// the support points of the 2 shapes that generated Points when the
+	// simplex is built on a Minkowski difference, Points[i] = A[i] - B[i].
+	// They are garbage if Merge was used instead of MergeSupport.
+	A, B [4]glm.Vec3
+
+	// the barycentric coordinates of the point of the simplex closest to
+	// origin, as computed by the last call to NearestToOrigin.
+	Weights [4]float32
+
 	// the current extend of the simplex.
 	Size int
 }
@@ -23,68 +32,202 @@ func (s *Simplex) Merge(u *glm.Vec3) {
 	s.Size++
 }
 
+// MergeSupport merges a-b to the simplex and remembers a and b so they can be
+// used to compute witness points. This will panic if you add a 5th vertex.
+func (s *Simplex) MergeSupport(a, b *glm.Vec3) {
+	s.A[s.Size] = *a
+	s.B[s.Size] = *b
+	s.Points[s.Size].SubOf(a, b)
+	s.Size++
+}
+
+// ClosestPoint returns the point of the simplex closest to origin, as computed
+// by the last call to NearestToOrigin.
+func (s *Simplex) ClosestPoint() glm.Vec3 {
+	var p glm.Vec3
+	for n := 0; n < s.Size; n++ {
+		p.AddScaledVec(s.Weights[n], &s.Points[n])
+	}
+	return p
+}
+
+// Witnesses returns the points of the 2 shapes whose difference is the point
+// returned by ClosestPoint. Only valid if the simplex was built with
+// MergeSupport.
+func (s *Simplex) Witnesses() (a, b glm.Vec3) {
+	for n := 0; n < s.Size; n++ {
+		a.AddScaledVec(s.Weights[n], &s.A[n])
+		b.AddScaledVec(s.Weights[n], &s.B[n])
+	}
+	return
+}
+
 // NearestToOrigin modifies the simplex to contain only the minimum amount of
 // points required to describe the direction to origin, it also returns the next
 // direction to search in GJK and true if the origin is contained in the simplex
+//
+// The reduction is done with the signed volumes sub-algorithm, the barycentric
+// coordinates of the point closest to origin are stored in Weights.
 func (s *Simplex) NearestToOrigin() (direction glm.Vec3, containsOrigin bool) {
-	// TODO(hydroflame): implement
-	const (
-		a = iota
-		b
-		c
-		d
+	var (
+		weights [4]float32
+		indices [4]int
+		size    int
 	)
 
 	switch s.Size {
 	case 4:
-		ab := s.Points[b].Sub(&s.Points[a])
-		ac := s.Points[c].Sub(&s.Points[a])
-		ad := s.Points[d].Sub(&s.Points[a])
-		ap := s.Points[a].Inverse()
-
-		// Check if Origin is in vertex region outside A
-		d1, d2, d3 := ab.Dot(&ap), ac.Dot(&ap), ad.Dot(&ap)
-		if d1 <= 0 && d2 <= 0 && d3 <= 0 {
-			// inside voronoi region of A
-			fmt.Print("		A")
-			return glm.Vec3{}, false
+		size, containsOrigin = s.nearest3D(&indices, &weights)
+	case 3:
+		size = s.nearest2D(0, 1, 2, &indices, &weights)
+	case 2:
+		size = s.nearest1D(0, 1, &indices, &weights)
+	case 1:
+		size, weights[0] = 1, 1
+	default: //case Size < 1 || Size > 4
+		panic("Simplex.Size=" + strconv.Itoa(int(s.Size)) + ", need 1, 2, 3, or 4")
+	}
+
+	// Keep only the points that support the closest point. indices is sorted
+	// so we can compact the arrays in place.
+	for n := 0; n < size; n++ {
+		s.Points[n] = s.Points[indices[n]]
+		s.A[n] = s.A[indices[n]]
+		s.B[n] = s.B[indices[n]]
+		s.Weights[n] = weights[n]
+	}
+	s.Size = size
+
+	direction = s.ClosestPoint()
+	direction.Invert()
+	return
+}
+
+// nearest1D finds the point closest to origin on the segment {i j}.
+func (s *Simplex) nearest1D(i, j int, indices *[4]int, weights *[4]float32) int {
+	a, b := &s.Points[i], &s.Points[j]
+	ab := b.Sub(a)
+	denom := ab.Len2()
+	t := -a.Dot(&ab)
+
+	if t <= 0 || denom == 0 {
+		indices[0], weights[0] = i, 1
+		return 1
+	}
+	if t >= denom {
+		indices[0], weights[0] = j, 1
+		return 1
+	}
+	t /= denom
+	indices[0], indices[1] = i, j
+	weights[0], weights[1] = 1-t, t
+	return 2
+}
+
+// nearest2D finds the point closest to origin on the triangle {i j k}, with
+// i < j < k.
+func (s *Simplex) nearest2D(i, j, k int, indices *[4]int, weights *[4]float32) int {
+	a, b, c := &s.Points[i], &s.Points[j], &s.Points[k]
+	ab, ac := b.Sub(a), c.Sub(a)
+	n := ab.Cross(&ac)
+	nn := n.Len2()
+
+	// Signed areas of the sub-triangles formed with the projection of origin
+	// on the plane of the triangle.
+	bc, ca, abx := b.Cross(c), c.Cross(a), a.Cross(b)
+	u, v, w := bc.Dot(&n), ca.Dot(&n), abx.Dot(&n)
+
+	if nn > 0 && u > 0 && v > 0 && w > 0 {
+		indices[0], indices[1], indices[2] = i, j, k
+		weights[0], weights[1], weights[2] = u/nn, v/nn, w/nn
+		return 3
+	}
+
+	// The origin projects outside the triangle (or the triangle is
+	// degenerate), the closest point is on one of the edges.
+	best := float32(-1)
+	var size int
+	for _, edge := range [3][2]int{{i, j}, {j, k}, {i, k}} {
+		var ei [4]int
+		var ew [4]float32
+		esize := s.nearest1D(edge[0], edge[1], &ei, &ew)
+		var p glm.Vec3
+		for m := 0; m < esize; m++ {
+			p.AddScaledVec(ew[m], &s.Points[ei[m]])
 		}
+		if d := p.Len2(); best < 0 || d < best {
+			best, size = d, esize
+			*indices, *weights = ei, ew
+		}
+	}
+	return size
+}
+
+// nearest3D finds the point closest to origin in the tetrahedron. It returns
+// true if the origin is inside.
+func (s *Simplex) nearest3D(indices *[4]int, weights *[4]float32) (int, bool) {
+	p := &s.Points
+
+	// Signed volume of the tetrahedron and of the 4 tetrahedrons formed by
+	// replacing each vertex by the origin.
+	vol := [4]float32{
+		glm.ScalarTripleProduct(&p[1], &p[2], &p[3]),
+		-glm.ScalarTripleProduct(&p[0], &p[2], &p[3]),
+		glm.ScalarTripleProduct(&p[0], &p[1], &p[3]),
+		-glm.ScalarTripleProduct(&p[0], &p[1], &p[2]),
+	}
+	det := vol[0] + vol[1] + vol[2] + vol[3]
 
-		bp := s.Points[b].Inverse()
-		d4, d5, d6 := ab.Dot(&bp), ac.Dot(&bp), ad.Dot(&bp)
-		if d4 >= 0 && d5 <= d4 && d6 <= d4 {
-			// inside voronoi region of B
-			fmt.Print("		B")
-			return glm.Vec3{}, false
+	if det != 0 && sameSign(vol[0], det) && sameSign(vol[1], det) && sameSign(vol[2], det) && sameSign(vol[3], det) {
+		for n := 0; n < 4; n++ {
+			indices[n], weights[n] = n, vol[n]/det
 		}
+		return 4, true
+	}
 
-		cp := s.Points[c].Inverse()
-		d7, d8, d9 := ac.Dot(&cp), ab.Dot(&cp), ad.Dot(&cp)
-		if d7 >= 0 && d8 <= d7 && d9 <= d7 {
-			// inside voronoi region of C
-			fmt.Print("		C")
-			return glm.Vec3{}, false
+	// The origin is outside, the closest point is on one of the faces.
+	best := float32(-1)
+	var size int
+	for _, face := range [4][3]int{{1, 2, 3}, {0, 2, 3}, {0, 1, 3}, {0, 1, 2}} {
+		var fi [4]int
+		var fw [4]float32
+		fsize := s.nearest2D(face[0], face[1], face[2], &fi, &fw)
+		var q glm.Vec3
+		for m := 0; m < fsize; m++ {
+			q.AddScaledVec(fw[m], &s.Points[fi[m]])
+		}
+		if d := q.Len2(); best < 0 || d < best {
+			best, size = d, fsize
+			*indices, *weights = fi, fw
 		}
+	}
+	return size, false
+}
 
-		dp := s.Points[d].Inverse()
-		d10, d11, d12 := ad.Dot(&dp), ab.Dot(&dp), ac.Dot(&dp)
-		if d10 >= 0 && d11 <= d10 && d12 <= d10 {
-			// inside voronoi region of D
-			fmt.Print("		D")
-			return glm.Vec3{}, false
+// contains returns true if w is one of the points of the simplex.
+func (s *Simplex) contains(w *glm.Vec3) bool {
+	for n := 0; n < s.Size; n++ {
+		if s.Points[n] == *w {
+			return true
 		}
+	}
+	return false
+}
 
-		vab := d1*d5 - d2*d6 - d3*d4
-		if vab <= 0 && d1 >= 0 && d4 <= 0 {
-			fmt.Print("		yes")
-			fmt.Print("	", s.Points)
+// maxLen2 returns the square length of the point of the simplex the furthest
+// from origin.
+func (s *Simplex) maxLen2() float32 {
+	var max float32
+	for n := 0; n < s.Size; n++ {
+		if l := s.Points[n].Len2(); l > max {
+			max = l
 		}
-	case 3:
-	case 2:
-	case 1:
-	default: //case Size < 1 || Size > 4
-		panic("Simplex.Size=" + strconv.Itoa(int(s.Size)) + ", need 1, 2, 3, or 4")
 	}
+	return max
+}
 
-	return glm.Vec3{}, false
+// sameSign returns true if a and b are both strictly positive or both
+// strictly negative.
+func sameSign(a, b float32) bool {
+	return (a > 0 && b > 0) || (a < 0 && b < 0)
 }
@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"testing"
+)
+
+func TestSimplex_NearestToOrigin(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		points         []glm.Vec3
+		closest        glm.Vec3
+		size           int
+		containsOrigin bool
+	}{
+		{ // 0 point
+			points:  []glm.Vec3{{1, 2, 3}},
+			closest: glm.Vec3{1, 2, 3},
+			size:    1,
+		},
+		{ // 1 segment, interior
+			points:  []glm.Vec3{{-1, 1, 0}, {1, 1, 0}},
+			closest: glm.Vec3{0, 1, 0},
+			size:    2,
+		},
+		{ // 2 segment, vertex
+			points:  []glm.Vec3{{1, 1, 0}, {2, 1, 0}},
+			closest: glm.Vec3{1, 1, 0},
+			size:    1,
+		},
+		{ // 3 triangle, face
+			points:  []glm.Vec3{{-1, -1, 1}, {1, -1, 1}, {0, 1, 1}},
+			closest: glm.Vec3{0, 0, 1},
+			size:    3,
+		},
+		{ // 4 triangle, edge
+			points:  []glm.Vec3{{-1, 1, 1}, {1, 1, 1}, {0, 3, 1}},
+			closest: glm.Vec3{0, 1, 1},
+			size:    2,
+		},
+		{ // 5 triangle, vertex
+			points:  []glm.Vec3{{1, 1, 0}, {2, 1, 0}, {1, 2, 0}},
+			closest: glm.Vec3{1, 1, 0},
+			size:    1,
+		},
+		{ // 6 tetrahedron, contains origin
+			points:         []glm.Vec3{{-1, -1, -1}, {1, -1, -1}, {0, 1, -1}, {0, 0, 1}},
+			closest:        glm.Vec3{0, 0, 0},
+			size:           4,
+			containsOrigin: true,
+		},
+		{ // 7 tetrahedron, face
+			points:  []glm.Vec3{{-1, -1, 1}, {1, -1, 1}, {0, 1, 1}, {0, 0, 3}},
+			closest: glm.Vec3{0, 0, 1},
+			size:    3,
+		},
+		{ // 8 tetrahedron, edge
+			points:  []glm.Vec3{{-1, 1, 0}, {1, 1, 0}, {0, 2, 1}, {0, 2, -1}},
+			closest: glm.Vec3{0, 1, 0},
+			size:    2,
+		},
+	}
+
+	for i, test := range tests {
+		var s Simplex
+		for n := range test.points {
+			s.Merge(&test.points[n])
+		}
+		direction, containsOrigin := s.NearestToOrigin()
+		closest := direction.Inverse()
+		if !closest.EqualThreshold(&test.closest, 1e-5) || s.Size != test.size || containsOrigin != test.containsOrigin {
+			t.Errorf("[%d] closest = %v, size = %d, contains = %t, want %v, %d, %t", i,
+				closest, s.Size, containsOrigin, test.closest, test.size, test.containsOrigin)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+)
+
+// SpatialHash is a uniform grid of cubic cells stored in a hash map, so only
+// the cells that contain something use memory. Entries are points or AABB,
+// an AABB is stored in every cell it overlaps so the cells should be about
+// the size of the entries.
+type SpatialHash struct {
+	cellSize, invCellSize float32
+
+	cells   map[spatialCell][]int
+	entries []spatialEntry
+	free    []int
+
+	// the cells that ever contained an entry are all in [min, max].
+	min, max spatialCell
+
+	// incremented by every query, entries already visited by the query are
+	// marked with it.
+	stamp int
+}
+
+// spatialCell is the integer coordinates of a cell of a SpatialHash.
+type spatialCell struct {
+	x, y, z int
+}
+
+// spatialEntry is an entry of a SpatialHash. Points are AABB with no extend.
+type spatialEntry struct {
+	aabb AABB
+	data interface{}
+
+	// the range of cells the entry is in.
+	min, max spatialCell
+
+	alive bool
+	stamp int
+}
+
+// NewSpatialHash returns an empty spatial hash whose cells are cubes of side
+// cellSize.
+func NewSpatialHash(cellSize float32) *SpatialHash {
+	return &SpatialHash{
+		cellSize:    cellSize,
+		invCellSize: 1 / cellSize,
+		cells:       make(map[spatialCell][]int),
+		min:         spatialCell{math.MaxInt32, math.MaxInt32, math.MaxInt32},
+		max:         spatialCell{math.MinInt32, math.MinInt32, math.MinInt32},
+	}
+}
+
+// InsertPoint adds the point p and returns its id. data can be retrieved with
+// Data.
+func (h *SpatialHash) InsertPoint(p *glm.Vec3, data interface{}) int {
+	return h.InsertAABB(&AABB{Center: *p}, data)
+}
+
+// InsertAABB adds the AABB a and returns its id. data can be retrieved with
+// Data.
+func (h *SpatialHash) InsertAABB(a *AABB, data interface{}) int {
+	var id int
+	if len(h.free) > 0 {
+		id = h.free[len(h.free)-1]
+		h.free = h.free[:len(h.free)-1]
+	} else {
+		id = len(h.entries)
+		h.entries = append(h.entries, spatialEntry{})
+	}
+
+	e := &h.entries[id]
+	*e = spatialEntry{aabb: *a, data: data, alive: true}
+	e.min, e.max = h.cellRange(a)
+	h.link(id)
+	return id
+}
+
+// Remove removes the entry. Its id might be reused by a future insertion.
+func (h *SpatialHash) Remove(id int) {
+	h.unlink(id)
+	h.entries[id] = spatialEntry{}
+	h.free = append(h.free, id)
+}
+
+// MovePoint moves the point entry to p.
+func (h *SpatialHash) MovePoint(id int, p *glm.Vec3) {
+	h.MoveAABB(id, &AABB{Center: *p})
+}
+
+// MoveAABB moves the entry to the AABB a.
+func (h *SpatialHash) MoveAABB(id int, a *AABB) {
+	e := &h.entries[id]
+	e.aabb = *a
+	min, max := h.cellRange(a)
+	if min == e.min && max == e.max {
+		return
+	}
+	h.unlink(id)
+	e.min, e.max = min, max
+	h.link(id)
+}
+
+// AABB returns the AABB of the entry, with no extend for points.
+func (h *SpatialHash) AABB(id int) AABB {
+	return h.entries[id].aabb
+}
+
+// Data returns the user data of the entry.
+func (h *SpatialHash) Data(id int) interface{} {
+	return h.entries[id].data
+}
+
+// QueryAABB calls callback with the id of every entry that overlaps a. The
+// query stops if callback returns false.
+func (h *SpatialHash) QueryAABB(a *AABB, callback func(id int) bool) {
+	min, max := h.cellRange(a)
+	h.query(min, max, func(e *spatialEntry) bool {
+		return TestAABBAABB(a, &e.aabb)
+	}, callback)
+}
+
+// QueryRadius calls callback with the id of every entry within radius of
+// center. The query stops if callback returns false.
+func (h *SpatialHash) QueryRadius(center *glm.Vec3, radius float32, callback func(id int) bool) {
+	min, max := h.cellRange(&AABB{Center: *center, HalfExtend: glm.Vec3{radius, radius, radius}})
+	h.query(min, max, func(e *spatialEntry) bool {
+		return SqDistAABBPoint(&e.aabb, center) <= radius*radius
+	}, callback)
+}
+
+// Nearest returns the entry closest to p and its distance to p, 0 if p is
+// inside an AABB entry. Entries further than maxDistance are ignored. It
+// returns false if there is no such entry.
+func (h *SpatialHash) Nearest(p *glm.Vec3, maxDistance float32) (id int, distance float32, ok bool) {
+	id = -1
+	best := maxDistance * maxDistance
+	h.stamp++
+
+	// Visit the rings of cells around the cell of p until they are further
+	// than the closest entry found. The entries not visited by the first r
+	// rings are at least (r-1)*cellSize away from p.
+	c := h.cell(p)
+	for r := 0; ; r++ {
+		if d := float32(r-1) * h.cellSize; (ok && d*d >= best) || d > maxDistance {
+			break
+		}
+		if c.x-r < h.min.x && c.y-r < h.min.y && c.z-r < h.min.z &&
+			c.x+r > h.max.x && c.y+r > h.max.y && c.z+r > h.max.z {
+			break
+		}
+
+		for x := c.x - r; x <= c.x+r; x++ {
+			if x < h.min.x || x > h.max.x {
+				continue
+			}
+			for y := c.y - r; y <= c.y+r; y++ {
+				if y < h.min.y || y > h.max.y {
+					continue
+				}
+				// Inside the ring, only the 2 cells on its faces along z.
+				step := 1
+				if r > 0 && x != c.x-r && x != c.x+r && y != c.y-r && y != c.y+r {
+					step = 2 * r
+				}
+				for z := c.z - r; z <= c.z+r; z += step {
+					for _, n := range h.cells[spatialCell{x, y, z}] {
+						e := &h.entries[n]
+						if e.stamp == h.stamp {
+							continue
+						}
+						e.stamp = h.stamp
+						if d := SqDistAABBPoint(&e.aabb, p); d <= best && (!ok || d < best) {
+							id, best, ok = n, d, true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if ok {
+		distance = math.Sqrt(best)
+	}
+	return
+}
+
+// query calls callback with the entries in the cells [min, max] that pass
+// test. If there are more cells than entries it tests every entry instead.
+func (h *SpatialHash) query(min, max spatialCell, test func(e *spatialEntry) bool, callback func(id int) bool) {
+	cells := float32(max.x-min.x+1) * float32(max.y-min.y+1) * float32(max.z-min.z+1)
+	if cells > float32(len(h.entries)) {
+		for n := range h.entries {
+			if e := &h.entries[n]; e.alive && test(e) && !callback(n) {
+				return
+			}
+		}
+		return
+	}
+
+	h.stamp++
+	for x := min.x; x <= max.x; x++ {
+		for y := min.y; y <= max.y; y++ {
+			for z := min.z; z <= max.z; z++ {
+				for _, n := range h.cells[spatialCell{x, y, z}] {
+					e := &h.entries[n]
+					if e.stamp == h.stamp {
+						continue
+					}
+					e.stamp = h.stamp
+					if test(e) && !callback(n) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// cell returns the cell containing p.
+func (h *SpatialHash) cell(p *glm.Vec3) spatialCell {
+	return spatialCell{
+		int(math.Floor(p[0] * h.invCellSize)),
+		int(math.Floor(p[1] * h.invCellSize)),
+		int(math.Floor(p[2] * h.invCellSize)),
+	}
+}
+
+// cellRange returns the range of cells overlapped by a.
+func (h *SpatialHash) cellRange(a *AABB) (min, max spatialCell) {
+	lo, hi := a.Center.Sub(&a.HalfExtend), a.Center.Add(&a.HalfExtend)
+	return h.cell(&lo), h.cell(&hi)
+}
+
+// link adds the entry to the cells it overlaps.
+func (h *SpatialHash) link(id int) {
+	e := &h.entries[id]
+	for x := e.min.x; x <= e.max.x; x++ {
+		for y := e.min.y; y <= e.max.y; y++ {
+			for z := e.min.z; z <= e.max.z; z++ {
+				c := spatialCell{x, y, z}
+				h.cells[c] = append(h.cells[c], id)
+			}
+		}
+	}
+
+	h.min.x, h.max.x = minInt(h.min.x, e.min.x), maxInt(h.max.x, e.max.x)
+	h.min.y, h.max.y = minInt(h.min.y, e.min.y), maxInt(h.max.y, e.max.y)
+	h.min.z, h.max.z = minInt(h.min.z, e.min.z), maxInt(h.max.z, e.max.z)
+}
+
+// unlink removes the entry from the cells it overlaps.
+func (h *SpatialHash) unlink(id int) {
+	e := &h.entries[id]
+	for x := e.min.x; x <= e.max.x; x++ {
+		for y := e.min.y; y <= e.max.y; y++ {
+			for z := e.min.z; z <= e.max.z; z++ {
+				c := spatialCell{x, y, z}
+				ids := h.cells[c]
+				for n := range ids {
+					if ids[n] == id {
+						ids[n] = ids[len(ids)-1]
+						ids = ids[:len(ids)-1]
+						break
+					}
+				}
+				if len(ids) == 0 {
+					delete(h.cells, c)
+				} else {
+					h.cells[c] = ids
+				}
+			}
+		}
+	}
+}
+
+// minInt returns the smallest of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,167 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSpatialHash(t *testing.T) {
+	t.Parallel()
+	h := NewSpatialHash(1)
+	a := h.InsertPoint(&glm.Vec3{0.5, 0.5, 0.5}, "a")
+	b := h.InsertPoint(&glm.Vec3{-3, 0, 0}, "b")
+	c := h.InsertAABB(&AABB{Center: glm.Vec3{5, 0, 0}, HalfExtend: glm.Vec3{2, 2, 2}}, "c")
+	if h.Data(c) != "c" {
+		t.Errorf("Data = %v, want c", h.Data(c))
+	}
+
+	tests := []struct {
+		p       glm.Vec3
+		radius  float32
+		found   []int
+		nearest int
+		dist    float32
+	}{
+		{glm.Vec3{0, 0, 0}, 1, []int{a}, a, math.Sqrt(0.75)},
+		{glm.Vec3{-2, 0, 0}, 1, []int{b}, b, 1},
+		{glm.Vec3{2, 0, 0}, 1, []int{c}, c, 1},
+		{glm.Vec3{5, 1, -1}, 0, []int{c}, c, 0},
+		{glm.Vec3{0, 10, 0}, 5, nil, c, 8.5440},
+		{glm.Vec3{-1, 0, 0}, 2.5, []int{a, b}, a, 1.6583},
+	}
+
+	for i, test := range tests {
+		var found []int
+		h.QueryRadius(&test.p, test.radius, func(id int) bool {
+			found = append(found, id)
+			return true
+		})
+		sort.Ints(found)
+		if !equalInts(found, test.found) {
+			t.Errorf("[%d] QueryRadius = %v, want %v", i, found, test.found)
+		}
+		id, dist, ok := h.Nearest(&test.p, math.MaxFloat32)
+		if !ok || id != test.nearest || !glm.FloatEqualThreshold(dist, test.dist, 1e-4) {
+			t.Errorf("[%d] Nearest = %d at %f %t, want %d at %f", i, id, dist, ok, test.nearest, test.dist)
+		}
+		if _, _, ok := h.Nearest(&test.p, test.dist/2); ok && test.dist > 0 {
+			t.Errorf("[%d] Nearest within %f found an entry", i, test.dist/2)
+		}
+	}
+
+	h.MovePoint(a, &glm.Vec3{-3, 1, 0})
+	h.Remove(c)
+	var found []int
+	h.QueryAABB(&AABB{Center: glm.Vec3{-3, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}, func(id int) bool {
+		found = append(found, id)
+		return true
+	})
+	sort.Ints(found)
+	if want := []int{a, b}; !equalInts(found, want) {
+		t.Errorf("QueryAABB = %v, want %v", found, want)
+	}
+	if id, _, _ := h.Nearest(&glm.Vec3{5, 0, 0}, math.MaxFloat32); id == c {
+		t.Errorf("Nearest found a removed entry")
+	}
+}
+
+func TestSpatialHash_Empty(t *testing.T) {
+	t.Parallel()
+	h := NewSpatialHash(1)
+	if _, _, ok := h.Nearest(&glm.Vec3{}, math.MaxFloat32); ok {
+		t.Errorf("Nearest on an empty hash found an entry")
+	}
+}
+
+func TestSpatialHash_BruteForce(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	h := NewSpatialHash(4)
+	aabbs := make(map[int]AABB)
+	for n := 0; n < 500; n++ {
+		aabb := randomAABB(r)
+		if n%2 == 0 {
+			aabb.HalfExtend = glm.Vec3{}
+		}
+		aabbs[h.InsertAABB(&aabb, nil)] = aabb
+	}
+	for id, aabb := range aabbs {
+		switch r.Intn(4) {
+		case 0:
+			h.Remove(id)
+			delete(aabbs, id)
+		case 1:
+			aabb.Center.AddWith(&glm.Vec3{r.Float32()*10 - 5, r.Float32()*10 - 5, r.Float32()*10 - 5})
+			h.MoveAABB(id, &aabb)
+			aabbs[id] = aabb
+		}
+	}
+
+	for n := 0; n < 200; n++ {
+		p := glm.Vec3{r.Float32()*140 - 70, r.Float32()*140 - 70, r.Float32()*140 - 70}
+		radius := r.Float32() * 20
+		query := randomAABB(r)
+		query.HalfExtend.MulWith(r.Float32() * 10)
+
+		var wantRadius, wantAABB []int
+		wantDist := float32(math.MaxFloat32)
+		for id, aabb := range aabbs {
+			d := SqDistAABBPoint(&aabb, &p)
+			if d <= radius*radius {
+				wantRadius = append(wantRadius, id)
+			}
+			if TestAABBAABB(&aabb, &query) {
+				wantAABB = append(wantAABB, id)
+			}
+			wantDist = math.Min(wantDist, math.Sqrt(d))
+		}
+		sort.Ints(wantRadius)
+		sort.Ints(wantAABB)
+
+		var gotRadius, gotAABB []int
+		h.QueryRadius(&p, radius, func(id int) bool {
+			gotRadius = append(gotRadius, id)
+			return true
+		})
+		h.QueryAABB(&query, func(id int) bool {
+			gotAABB = append(gotAABB, id)
+			return true
+		})
+		sort.Ints(gotRadius)
+		sort.Ints(gotAABB)
+		if !equalInts(gotRadius, wantRadius) {
+			t.Errorf("[%d] QueryRadius = %v, want %v", n, gotRadius, wantRadius)
+		}
+		if !equalInts(gotAABB, wantAABB) {
+			t.Errorf("[%d] QueryAABB = %v, want %v", n, gotAABB, wantAABB)
+		}
+
+		id, dist, ok := h.Nearest(&p, math.MaxFloat32)
+		if !ok || !glm.FloatEqualThreshold(dist, wantDist, 1e-4) {
+			t.Errorf("[%d] Nearest = %d at %f, want %f", n, id, dist, wantDist)
+		}
+		if ok {
+			aabb := aabbs[id]
+			if d := math.Sqrt(SqDistAABBPoint(&aabb, &p)); d != dist {
+				t.Errorf("[%d] Nearest = %d at %f, it is at %f", n, id, dist, d)
+			}
+		}
+	}
+}
+
+func BenchmarkSpatialHash_QueryRadius(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	h := NewSpatialHash(4)
+	for n := 0; n < 10000; n++ {
+		h.InsertPoint(&glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}, nil)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := glm.Vec3{r.Float32()*100 - 50, r.Float32()*100 - 50, r.Float32()*100 - 50}
+		h.QueryRadius(&p, 4, func(id int) bool { return true })
+	}
+}
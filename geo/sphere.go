@@ -106,3 +106,12 @@ func RitterEigenSphere(points []glm.Vec3) Sphere {
 	}
 	return s
 }
+
+// Support returns the point of the sphere furthest along direction.
+func (s *Sphere) Support(direction glm.Vec3) glm.Vec3 {
+	p := s.Center
+	if l2 := direction.Len2(); l2 > 0 {
+		p.AddScaledVec(s.Radius/math.Sqrt(l2), &direction)
+	}
+	return p
+}
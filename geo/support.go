@@ -0,0 +1,29 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+)
+
+// Support is implemented by convex shapes. It is the only thing algorithms
+// like GJK need to know about a shape.
+type Support interface {
+	// Support returns the point of the shape that is the furthest along
+	// direction. direction doesn't need to be normalized.
+	Support(direction glm.Vec3) glm.Vec3
+}
+
+// Transformed is a shape placed in world space by Transform. The shape is
+// described in its local space and Transform maps local to world space, it
+// can contain scaling and shearing.
+type Transformed struct {
+	Shape     Support
+	Transform glm.Mat3x4
+}
+
+// Support returns the point of the transformed shape furthest along direction.
+func (t *Transformed) Support(direction glm.Vec3) glm.Vec3 {
+	// The support of M*S along d is M times the support of S along M^T*d.
+	local := t.Transform.TransformInverseDirection(&direction)
+	p := t.Shape.Support(local)
+	return t.Transform.Transform(&p)
+}
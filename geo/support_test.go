@@ -0,0 +1,136 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
+	"testing"
+)
+
+func TestSupport(t *testing.T) {
+	t.Parallel()
+	cube := []glm.Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {-1, 1, -1}, {1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {-1, 1, 1}, {1, 1, 1},
+	}
+	hull, err := Quickhull(cube)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dop DOP8
+	DOP8FromPoints(&dop, cube)
+
+	tests := []struct {
+		shape     Support
+		direction glm.Vec3
+		support   glm.Vec3
+	}{
+		{ // 0
+			shape:     &Sphere{Center: glm.Vec3{1, 2, 3}, Radius: 2},
+			direction: glm.Vec3{0, 5, 0},
+			support:   glm.Vec3{1, 4, 3},
+		},
+		{ // 1
+			shape:     &AABB{Center: glm.Vec3{1, 2, 3}, HalfExtend: glm.Vec3{1, 2, 3}},
+			direction: glm.Vec3{1, -1, 1},
+			support:   glm.Vec3{2, 0, 6},
+		},
+		{ // 2
+			shape: &OBB{
+				Center:      glm.Vec3{0, 0, 0},
+				Orientation: [3]glm.Vec3{{0, 1, 0}, {-1, 0, 0}, {0, 0, 1}},
+				HalfExtend:  glm.Vec3{1, 2, 3},
+			},
+			direction: glm.Vec3{1, 1, -1},
+			support:   glm.Vec3{2, 1, -3},
+		},
+		{ // 3
+			shape:     &Capsule{A: glm.Vec3{0, 0, 0}, B: glm.Vec3{0, 4, 0}, Radius: 1},
+			direction: glm.Vec3{0, 1, 0},
+			support:   glm.Vec3{0, 5, 0},
+		},
+		{ // 4
+			shape:     &Capsule{A: glm.Vec3{0, 0, 0}, B: glm.Vec3{0, 4, 0}, Radius: 1},
+			direction: glm.Vec3{-3, -0.5, 0},
+			support:   glm.Vec3{-0.98639392, -0.16439899, 0},
+		},
+		{ // 5
+			shape: &Rect{
+				Center:      glm.Vec3{0, 0, 1},
+				Orientation: [2]glm.Vec3{{1, 0, 0}, {0, 1, 0}},
+				HalfExtend:  glm.Vec2{1, 2},
+			},
+			direction: glm.Vec3{-1, 1, 1},
+			support:   glm.Vec3{-1, 2, 1},
+		},
+		{ // 6
+			shape:     &hull,
+			direction: glm.Vec3{1, -2, 0.5},
+			support:   glm.Vec3{1, -1, 1},
+		},
+		{ // 7
+			shape:     &dop,
+			direction: glm.Vec3{1, 0, 0},
+			support:   glm.Vec3{3, 0, 0},
+		},
+		{ // 8
+			shape:     &dop,
+			direction: glm.Vec3{0, -1, 0.1},
+			support:   glm.Vec3{0, -3, 0},
+		},
+		{ // 9 translated then rotated 90 degrees around z
+			shape: &Transformed{
+				Shape:     &AABB{HalfExtend: glm.Vec3{1, 2, 3}},
+				Transform: glm.Mat3x4{0, 1, 0, -1, 0, 0, 0, 0, 1, 10, 0, 0},
+			},
+			direction: glm.Vec3{1, 1, 1},
+			support:   glm.Vec3{12, 1, 3},
+		},
+	}
+
+	for i, test := range tests {
+		if support := test.shape.Support(test.direction); !support.EqualThreshold(&test.support, 1e-4) {
+			t.Errorf("[%d] Support(%v) = %v, want %v", i, test.direction, support, test.support)
+		}
+	}
+}
+
+func TestGJK_Shapes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b         Support
+		distance     float32
+		intersecting bool
+	}{
+		{ // 0
+			a:        &Sphere{Center: glm.Vec3{0, 0, 0}, Radius: 1},
+			b:        &AABB{Center: glm.Vec3{3, 3, 0}, HalfExtend: glm.Vec3{1, 1, 1}},
+			distance: math.Sqrt(8) - 1,
+		},
+		{ // 1
+			a:        &Capsule{A: glm.Vec3{-5, 0, 0}, B: glm.Vec3{5, 0, 0}, Radius: 0.5},
+			b:        &Capsule{A: glm.Vec3{0, -5, 2}, B: glm.Vec3{0, 5, 2}, Radius: 0.5},
+			distance: 1,
+		},
+		{ // 2
+			a: &Transformed{
+				Shape:     &AABB{HalfExtend: glm.Vec3{1, 1, 1}},
+				Transform: glm.Mat3x4{1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 5},
+			},
+			b:        &Rect{Orientation: [2]glm.Vec3{{1, 0, 0}, {0, 1, 0}}, HalfExtend: glm.Vec2{3, 3}},
+			distance: 4,
+		},
+		{ // 3
+			a:            &Sphere{Center: glm.Vec3{0, 0, 0}, Radius: 1},
+			b:            &Capsule{A: glm.Vec3{0.5, -5, 0}, B: glm.Vec3{0.5, 5, 0}, Radius: 0.1},
+			intersecting: true,
+		},
+	}
+
+	for i, test := range tests {
+		distance, _, _, intersecting := GJK(test.a, test.b)
+		if intersecting != test.intersecting || !glm.FloatEqualThreshold(distance, test.distance, 1e-4) {
+			t.Errorf("[%d] distance = %f, intersecting = %t, want %f, %t", i,
+				distance, intersecting, test.distance, test.intersecting)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package geo
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ProxyPair is a pair of overlapping proxies of a broadphase, with A < B.
+type ProxyPair struct {
+	A, B int
+}
+
+// makeProxyPair returns the pair of a and b, ordered.
+func makeProxyPair(a, b int) ProxyPair {
+	if a > b {
+		a, b = b, a
+	}
+	return ProxyPair{a, b}
+}
+
+// SweepAndPrune is a sort and sweep broadphase. It keeps the endpoints of the
+// AABB of its proxies sorted along the x axis, or along all 3 axes, and
+// reports which pairs of proxies started or stopped overlapping at every
+// Update. The lists are sorted with an insertion sort, which is close to
+// linear when the proxies move little between updates.
+//
+// With 1 axis, Update sweeps the x axis to find the overlapping pairs. With 3
+// axes, the pairs are updated incrementally from the endpoints that swap
+// places, which is faster when few proxies move but uses more memory.
+type SweepAndPrune struct {
+	proxies []sapProxy
+
+	// the sorted endpoints of every axis.
+	axes [][]sapEndpoint
+
+	// the pairs overlapping as of the last sort.
+	pairs map[ProxyPair]struct{}
+
+	// with 3 axes, the pairs that changed since the last Update and whether
+	// they were overlapping then.
+	changed map[ProxyPair]bool
+
+	// the ids of the proxies removed since the last Update and of the proxies
+	// that can be reused. Removed ids are only reused after an Update so a
+	// pair is never reported for 2 different proxies.
+	removed, free []int
+}
+
+// sapProxy is a proxy of a SweepAndPrune.
+type sapProxy struct {
+	min, max [3]float32
+	data     interface{}
+	alive    bool
+}
+
+// sapEndpoint is the min or max of a proxy along an axis.
+type sapEndpoint struct {
+	value float32
+	proxy int
+	max   bool
+}
+
+// less returns true if e is sorted before f. A min is sorted before a max of
+// the same value so touching proxies overlap, like in TestAABBAABB.
+func (e *sapEndpoint) less(f *sapEndpoint) bool {
+	return e.value < f.value || (e.value == f.value && !e.max && f.max)
+}
+
+// NewSweepAndPrune returns an empty sweep and prune broadphase that sorts axes
+// axes, which must be 1 or 3.
+func NewSweepAndPrune(axes int) *SweepAndPrune {
+	if axes != 1 && axes != 3 {
+		panic("SweepAndPrune axes=" + strconv.Itoa(axes) + ", need 1 or 3")
+	}
+	return &SweepAndPrune{
+		axes:    make([][]sapEndpoint, axes),
+		pairs:   make(map[ProxyPair]struct{}),
+		changed: make(map[ProxyPair]bool),
+	}
+}
+
+// Insert adds a proxy for aabb and returns its id. data can be retrieved with
+// Data. The pairs of the proxy are reported by the next Update.
+func (s *SweepAndPrune) Insert(aabb *AABB, data interface{}) int {
+	var id int
+	if len(s.free) > 0 {
+		id = s.free[len(s.free)-1]
+		s.free = s.free[:len(s.free)-1]
+	} else {
+		id = len(s.proxies)
+		s.proxies = append(s.proxies, sapProxy{})
+	}
+	s.proxies[id] = sapProxy{data: data, alive: true}
+	s.setAABB(id, aabb)
+
+	// The endpoints start after every other endpoint, where the proxy doesn't
+	// overlap anything, and are sorted in place by the next Update.
+	for i := range s.axes {
+		s.axes[i] = append(s.axes[i],
+			sapEndpoint{value: s.proxies[id].min[i], proxy: id},
+			sapEndpoint{value: s.proxies[id].max[i], proxy: id, max: true})
+	}
+	return id
+}
+
+// Remove removes the proxy. Its pairs are reported as removed by the next
+// Update, after which its id might be reused by Insert.
+func (s *SweepAndPrune) Remove(id int) {
+	s.proxies[id].alive = false
+	s.proxies[id].data = nil
+	s.removed = append(s.removed, id)
+	if len(s.axes) == 1 {
+		return
+	}
+	for p := range s.pairs {
+		if p.A == id || p.B == id {
+			s.removePair(p)
+		}
+	}
+}
+
+// Move updates the AABB of the proxy. Its pairs are updated by the next
+// Update.
+func (s *SweepAndPrune) Move(id int, aabb *AABB) {
+	s.setAABB(id, aabb)
+}
+
+// AABB returns the AABB of the proxy.
+func (s *SweepAndPrune) AABB(id int) AABB {
+	p := &s.proxies[id]
+	var aabb AABB
+	for i := 0; i < 3; i++ {
+		aabb.Center[i] = (p.min[i] + p.max[i]) / 2
+		aabb.HalfExtend[i] = (p.max[i] - p.min[i]) / 2
+	}
+	return aabb
+}
+
+// Data returns the user data of the proxy.
+func (s *SweepAndPrune) Data(id int) interface{} {
+	return s.proxies[id].data
+}
+
+// Pairs calls callback once for every pair of proxies overlapping as of the
+// last Update, with a < b.
+func (s *SweepAndPrune) Pairs(callback func(a, b int)) {
+	for p := range s.pairs {
+		if s.proxies[p.A].alive && s.proxies[p.B].alive {
+			callback(p.A, p.B)
+		}
+	}
+}
+
+// Update sorts the endpoints and returns the pairs of proxies that started
+// and stopped overlapping since the last Update, sorted.
+func (s *SweepAndPrune) Update() (added, removed []ProxyPair) {
+	for i := range s.axes {
+		s.refresh(i)
+	}
+	s.free = append(s.free, s.removed...)
+	s.removed = s.removed[:0]
+
+	if len(s.axes) == 1 {
+		s.sort(0, false)
+		pairs := s.sweep()
+		for p := range pairs {
+			if _, ok := s.pairs[p]; !ok {
+				added = append(added, p)
+			}
+		}
+		for p := range s.pairs {
+			if _, ok := pairs[p]; !ok {
+				removed = append(removed, p)
+			}
+		}
+		s.pairs = pairs
+	} else {
+		for i := range s.axes {
+			s.sort(i, true)
+		}
+		for p, was := range s.changed {
+			_, ok := s.pairs[p]
+			if ok && !was {
+				added = append(added, p)
+			} else if !ok && was {
+				removed = append(removed, p)
+			}
+			delete(s.changed, p)
+		}
+	}
+
+	sortProxyPairs(added)
+	sortProxyPairs(removed)
+	return
+}
+
+// setAABB stores the bounds of aabb in the proxy.
+func (s *SweepAndPrune) setAABB(id int, aabb *AABB) {
+	p := &s.proxies[id]
+	for i := 0; i < 3; i++ {
+		p.min[i] = aabb.Center[i] - aabb.HalfExtend[i]
+		p.max[i] = aabb.Center[i] + aabb.HalfExtend[i]
+	}
+}
+
+// refresh drops the endpoints of the removed proxies from axis i and copies
+// the bounds of the others in their endpoints.
+func (s *SweepAndPrune) refresh(i int) {
+	endpoints := s.axes[i][:0]
+	for _, e := range s.axes[i] {
+		p := &s.proxies[e.proxy]
+		if !p.alive {
+			continue
+		}
+		if e.max {
+			e.value = p.max[i]
+		} else {
+			e.value = p.min[i]
+		}
+		endpoints = append(endpoints, e)
+	}
+	s.axes[i] = endpoints
+}
+
+// sort sorts the endpoints of axis i with an insertion sort. If events is
+// true the pairs are updated whenever a min and a max swap places.
+func (s *SweepAndPrune) sort(i int, events bool) {
+	endpoints := s.axes[i]
+	for j := 1; j < len(endpoints); j++ {
+		e := endpoints[j]
+		k := j
+		for ; k > 0 && e.less(&endpoints[k-1]); k-- {
+			other := &endpoints[k-1]
+			if events && e.max != other.max {
+				if e.max {
+					// e's proxy now ends before other's starts.
+					s.removePair(makeProxyPair(e.proxy, other.proxy))
+				} else if s.overlap(e.proxy, other.proxy) {
+					// e's proxy now starts before other's ends.
+					s.addPair(makeProxyPair(e.proxy, other.proxy))
+				}
+			}
+			endpoints[k] = *other
+		}
+		endpoints[k] = e
+	}
+}
+
+// sweep returns the overlapping pairs by sweeping the sorted endpoints of the
+// first axis.
+func (s *SweepAndPrune) sweep() map[ProxyPair]struct{} {
+	pairs := make(map[ProxyPair]struct{}, len(s.pairs))
+	var active []int
+	for _, e := range s.axes[0] {
+		if e.max {
+			for n, id := range active {
+				if id == e.proxy {
+					active[n] = active[len(active)-1]
+					active = active[:len(active)-1]
+					break
+				}
+			}
+			continue
+		}
+		for _, id := range active {
+			if s.overlap(id, e.proxy) {
+				pairs[makeProxyPair(id, e.proxy)] = struct{}{}
+			}
+		}
+		active = append(active, e.proxy)
+	}
+	return pairs
+}
+
+// overlap returns true if the AABB of the proxies a and b overlap.
+func (s *SweepAndPrune) overlap(a, b int) bool {
+	pa, pb := &s.proxies[a], &s.proxies[b]
+	for i := 0; i < 3; i++ {
+		if pa.min[i] > pb.max[i] || pb.min[i] > pa.max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addPair marks the pair as overlapping.
+func (s *SweepAndPrune) addPair(p ProxyPair) {
+	if _, ok := s.pairs[p]; ok {
+		return
+	}
+	s.touch(p)
+	s.pairs[p] = struct{}{}
+}
+
+// removePair marks the pair as not overlapping.
+func (s *SweepAndPrune) removePair(p ProxyPair) {
+	if _, ok := s.pairs[p]; !ok {
+		return
+	}
+	s.touch(p)
+	delete(s.pairs, p)
+}
+
+// touch remembers whether the pair was overlapping at the last Update before
+// it changes.
+func (s *SweepAndPrune) touch(p ProxyPair) {
+	if _, ok := s.changed[p]; !ok {
+		_, was := s.pairs[p]
+		s.changed[p] = was
+	}
+}
+
+// sortProxyPairs sorts the pairs by A then B.
+func sortProxyPairs(pairs []ProxyPair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+}
@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"github.com/engoengine/glm"
+	"math/rand"
+	"testing"
+)
+
+func TestSweepAndPrune_Update(t *testing.T) {
+	t.Parallel()
+	for _, axes := range []int{1, 3} {
+		s := NewSweepAndPrune(axes)
+		a := s.Insert(&AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}, "a")
+		b := s.Insert(&AABB{Center: glm.Vec3{5, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}, "b")
+		if s.Data(b) != "b" {
+			t.Errorf("[%d] Data = %v, want b", axes, s.Data(b))
+		}
+
+		steps := []struct {
+			center         glm.Vec3
+			added, removed []ProxyPair
+		}{
+			{glm.Vec3{5, 0, 0}, nil, nil},
+			// Touching overlaps.
+			{glm.Vec3{2, 0, 0}, []ProxyPair{{a, b}}, nil},
+			{glm.Vec3{1, 1, 0}, nil, nil},
+			// Only separated along y.
+			{glm.Vec3{1, 3, 0}, nil, []ProxyPair{{a, b}}},
+			{glm.Vec3{0, 0, 1}, []ProxyPair{{a, b}}, nil},
+			{glm.Vec3{0, 0, -5}, nil, []ProxyPair{{a, b}}},
+		}
+		for i, step := range steps {
+			s.Move(b, &AABB{Center: step.center, HalfExtend: glm.Vec3{1, 1, 1}})
+			added, removed := s.Update()
+			if !equalProxyPairs(added, step.added) || !equalProxyPairs(removed, step.removed) {
+				t.Errorf("[%d][%d] Update = %v, %v, want %v, %v", axes, i, added, removed, step.added, step.removed)
+			}
+		}
+
+		// Pairs of removed proxies are reported as removed.
+		s.Move(b, &AABB{HalfExtend: glm.Vec3{1, 1, 1}})
+		s.Update()
+		s.Remove(a)
+		if added, removed := s.Update(); len(added) != 0 || !equalProxyPairs(removed, []ProxyPair{{a, b}}) {
+			t.Errorf("[%d] Update after Remove = %v, %v, want [], %v", axes, added, removed, []ProxyPair{{a, b}})
+		}
+	}
+}
+
+func TestSweepAndPrune_BruteForce(t *testing.T) {
+	t.Parallel()
+	for _, axes := range []int{1, 3} {
+		r := rand.New(rand.NewSource(1))
+		s := NewSweepAndPrune(axes)
+		aabbs := make(map[int]AABB)
+		reported := make(map[ProxyPair]struct{})
+
+		for step := 0; step < 100; step++ {
+			for n := 0; n < 20 || len(aabbs) < 50; n++ {
+				aabb := randomAABB(r)
+				aabbs[s.Insert(&aabb, nil)] = aabb
+			}
+			for id, aabb := range aabbs {
+				switch r.Intn(10) {
+				case 0:
+					s.Remove(id)
+					delete(aabbs, id)
+				case 1, 2, 3, 4:
+					aabb.Center.AddWith(&glm.Vec3{r.Float32()*4 - 2, r.Float32()*4 - 2, r.Float32()*4 - 2})
+					s.Move(id, &aabb)
+					aabbs[id] = aabb
+				}
+			}
+
+			added, removed := s.Update()
+			for _, p := range added {
+				if _, ok := reported[p]; ok {
+					t.Fatalf("[%d][%d] %v added twice", axes, step, p)
+				}
+				reported[p] = struct{}{}
+			}
+			for _, p := range removed {
+				if _, ok := reported[p]; !ok {
+					t.Fatalf("[%d][%d] %v removed but not added", axes, step, p)
+				}
+				delete(reported, p)
+			}
+
+			want := make(map[ProxyPair]struct{})
+			for a, aabbA := range aabbs {
+				for b, aabbB := range aabbs {
+					if a < b && TestAABBAABB(&aabbA, &aabbB) {
+						want[ProxyPair{a, b}] = struct{}{}
+					}
+				}
+			}
+			if len(want) != len(reported) {
+				t.Fatalf("[%d][%d] %d pairs reported, want %d", axes, step, len(reported), len(want))
+			}
+			for p := range want {
+				if _, ok := reported[p]; !ok {
+					t.Fatalf("[%d][%d] %v not reported", axes, step, p)
+				}
+			}
+
+			var pairs int
+			s.Pairs(func(a, b int) {
+				if _, ok := want[ProxyPair{a, b}]; !ok {
+					t.Fatalf("[%d][%d] Pairs found %d %d", axes, step, a, b)
+				}
+				pairs++
+			})
+			if pairs != len(want) {
+				t.Fatalf("[%d][%d] Pairs found %d pairs, want %d", axes, step, pairs, len(want))
+			}
+		}
+	}
+}
+
+func equalProxyPairs(a, b []ProxyPair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkSweepAndPrune(b *testing.B, axes int) {
+	r := rand.New(rand.NewSource(1))
+	s := NewSweepAndPrune(axes)
+	aabbs := make([]AABB, 1000)
+	for n := range aabbs {
+		aabbs[n] = randomAABB(r)
+		s.Insert(&aabbs[n], nil)
+	}
+	s.Update()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for n := range aabbs {
+			aabbs[n].Center.AddWith(&glm.Vec3{r.Float32()*0.2 - 0.1, r.Float32()*0.2 - 0.1, r.Float32()*0.2 - 0.1})
+			s.Move(n, &aabbs[n])
+		}
+		s.Update()
+	}
+}
+
+func BenchmarkSweepAndPrune_1Axis(b *testing.B) {
+	benchmarkSweepAndPrune(b, 1)
+}
+
+func BenchmarkSweepAndPrune_3Axes(b *testing.B) {
+	benchmarkSweepAndPrune(b, 3)
+}
@@ -315,7 +315,7 @@ func ClosestPointSegmentSegment(p1, q1, p2, q2 *glm.Vec3) (s, t, u float32, c1,
 			if t < 0 {
 				t = 0
 				s = math.Clamp(-c/a, 0, 1)
-			} else {
+			} else if t > 1 {
 				t = 1
 				s = math.Clamp((b-c)/a, 0, 1)
 			}
@@ -326,7 +326,7 @@ func ClosestPointSegmentSegment(p1, q1, p2, q2 *glm.Vec3) (s, t, u float32, c1,
 	c2 = *p2
 
 	c1.AddScaledVec(s, &d1)
-	c2.AddScaledVec(s, &d2)
+	c2.AddScaledVec(t, &d2)
 
 	c1mc2 := c1.Sub(&c2)
 
@@ -427,7 +427,7 @@ func ClosestPointTrianglePoint(p, a, b, c *glm.Vec3) glm.Vec3 {
 	}
 
 	bp := p.Sub(b)
-	d3, d4 := ab.Dot(&bp), ac.Dot(&ap)
+	d3, d4 := ab.Dot(&bp), ac.Dot(&bp)
 	if d3 >= 0 && d4 <= d3 {
 		return *b // barycentric coordinates (0, 1, 0)
 	}
@@ -656,50 +656,41 @@ func TestSphereTriangle(s *Sphere, a, b, c *glm.Vec3) bool {
 
 // TestTriangleAABB returns true if [v0 v1 v2] intersects b
 func TestTriangleAABB(v0, v1, v2 *glm.Vec3, b *AABB) bool {
-	panic("implementation incomplete")
-	/*var p0, p2, r float32
-	//var p1 float32
 	// Translate triangle as conceptually moving AABB to origin
-	u0 := v0.Sub(&b.Center)
-	u1 := v1.Sub(&b.Center)
-	u2 := v2.Sub(&b.Center)
+	u := [3]glm.Vec3{v0.Sub(&b.Center), v1.Sub(&b.Center), v2.Sub(&b.Center)}
 	// Compute edge vectors for triangle
-	f0 := u1.Sub(&u0)
-	f1 := u2.Sub(&u1)
-	//f2 := u0.Sub(&u2)
-
-	// Test axes a00..a22 (category 3)
-	// Test axis a00
-	p0 = u0[2]*u1[1] - u0[1]*u1[2]
-	p2 = u2[2]*(u1[1]-u0[1]) - u2[2]*(u1[2]-u0[2])
-	r = b.HalfExtend[1]*math.Abs(f0[2]) + b.HalfExtend[2]*math.Abs(f0[1])
-	if math.Max(-math.Max(p0, p2), math.Min(p0, p2)) > r {
-		return false // Axis is a separating axis
+	f := [3]glm.Vec3{u[1].Sub(&u[0]), u[2].Sub(&u[1]), u[0].Sub(&u[2])}
+	e := &b.HalfExtend
+
+	// Test axes a00..a22 (category 3), the cross products of the axes of the
+	// AABB and the edges of the triangle
+	for i := 0; i < 3; i++ {
+		var axis glm.Vec3
+		axis[i] = 1
+		for j := 0; j < 3; j++ {
+			a := axis.Cross(&f[j])
+			p0, p1, p2 := u[0].Dot(&a), u[1].Dot(&a), u[2].Dot(&a)
+			r := e[0]*math.Abs(a[0]) + e[1]*math.Abs(a[1]) + e[2]*math.Abs(a[2])
+			if math.Max(-math.Max(p0, math.Max(p1, p2)), math.Min(p0, math.Min(p1, p2))) > r {
+				return false // Axis is a separating axis
+			}
+		}
 	}
-	// Repeat similar tests for remaining axes a01..a22
-	//...
+
 	// Test the three axes corresponding to the face normals of AABB b (category 1).
-	// Exit if...
-	// ... [-b.HalfExtend[0], b.HalfExtend[0]] and [min(u0[0],u1[0],u2[0]), max(u0[0],u1[0],u2[0])] do not overlap
-	if math.Max(u0[0], math.Max(u1[0], u2[0])) < -b.HalfExtend[0] ||
-		math.Min(u0[0], math.Min(u1[0], u2[0])) > b.HalfExtend[0] {
-		return false
-	}
-	// ... [-b.HalfExtend[1], b.HalfExtend[1]] and [min(u0[1],u1[1],u2[1]), max(u0[1],u1[1],u2[1])] do not overlap
-	if math.Max(u0[1], math.Max(u1[1], u2[1])) < -b.HalfExtend[1] ||
-		math.Min(u0[1], math.Min(u1[1], u2[1])) > b.HalfExtend[1] {
-		return false
-	}
-	// ... [-b.HalfExtend[2], b.HalfExtend[2]] and [min(u0[2],u1[2],u2[2]), max(u0[2],u1[2],u2[2])] do not overlap
-	if math.Max(u0[2], math.Max(u1[2], u2[2])) < -b.HalfExtend[2] ||
-		math.Min(u0[2], math.Min(u1[2], u2[2])) > b.HalfExtend[2] {
-		return false
+	// Exit if [-e[i], e[i]] and [min(u0[i],u1[i],u2[i]), max(u0[i],u1[i],u2[i])] do not overlap
+	for i := 0; i < 3; i++ {
+		if math.Max(u[0][i], math.Max(u[1][i], u[2][i])) < -e[i] ||
+			math.Min(u[0][i], math.Min(u[1][i], u[2][i])) > e[i] {
+			return false
+		}
 	}
+
 	// Test separating axis corresponding to triangle face normal (category 2)
 	var p Plane
-	p.N = f0.Cross(&f1)
-	p.P = u0
-	return TestAABBPlane(b, &p)*/
+	p.N = f[0].Cross(&f[1])
+	p.P = *v0
+	return TestAABBPlane(b, &p)
 }
 
 // IntersectSegmentPlane returns how far in the segment, the point in world
@@ -737,7 +728,8 @@ func IntersectRaySphere(p, d *glm.Vec3, s *Sphere) (t float32, q glm.Vec3, overl
 	if flops.Ltz(discr) {
 		return // returns false and all zero value
 	}
-	// Ray now found to intersect sphere, compute smallest t value of intersection t = -b - Sqrt(discr);
+	// Ray now found to intersect sphere, compute smallest t value of intersection
+	t = -b - math.Sqrt(discr)
 	// If t is negative, ray started inside sphere so clamp t to zero
 	if t < 0 {
 		t = 0
@@ -796,7 +788,7 @@ func IntersectRayAABB(p, d *glm.Vec3, a *AABB) (t float32, q glm.Vec3, overlap b
 			if t1 > t {
 				t = t1
 			}
-			if t2 > tmax {
+			if t2 < tmax {
 				tmax = t2
 			}
 			// Exit with no collision as soon as slab intersection becomes empty
@@ -2,6 +2,7 @@ package geo
 
 import (
 	"github.com/engoengine/glm"
+	"github.com/EngoEngine/math"
 	"math/rand"
 	"testing"
 )
@@ -186,3 +187,66 @@ func BenchmarkVariance1000(b *testing.B) {
 		Variance(data)
 	}
 }
+
+func TestTestTriangleAABB(t *testing.T) {
+	t.Parallel()
+	box := AABB{Center: glm.Vec3{0, 0, 0}, HalfExtend: glm.Vec3{1, 1, 1}}
+	tests := []struct {
+		a, b, c   glm.Vec3
+		intersect bool
+	}{
+		// Through the box.
+		{glm.Vec3{-5, -5, 0}, glm.Vec3{5, -5, 0}, glm.Vec3{0, 5, 0}, true},
+		// A vertex inside the box.
+		{glm.Vec3{0.5, 0.5, 0.5}, glm.Vec3{5, 5, 5}, glm.Vec3{5, 6, 5}, true},
+		// Separated along a face axis.
+		{glm.Vec3{2, -5, -5}, glm.Vec3{2, 5, -5}, glm.Vec3{2, 0, 5}, false},
+		// Separated by the plane of the triangle.
+		{glm.Vec3{3.5, 0, 0}, glm.Vec3{0, 3.5, 0}, glm.Vec3{0, 0, 3.5}, false},
+		{glm.Vec3{2, 0, 0}, glm.Vec3{0, 2, 0}, glm.Vec3{0, 0, 2}, true},
+		// Separated by an edge cross product only.
+		{glm.Vec3{2.2, 0, 0}, glm.Vec3{0, 2.2, 0}, glm.Vec3{5, 5, 5}, false},
+	}
+
+	for i, test := range tests {
+		if intersect := TestTriangleAABB(&test.a, &test.b, &test.c, &box); intersect != test.intersect {
+			t.Errorf("[%d] TestTriangleAABB(%v, %v, %v) = %t, want %t", i, test.a, test.b, test.c, intersect, test.intersect)
+		}
+	}
+}
+
+func TestClosestPointSegmentSegment(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		p1, q1, p2, q2 glm.Vec3
+		s, t, u        float32
+		c1, c2         glm.Vec3
+	}{
+		// Crossing in the middle of both segments.
+		{glm.Vec3{-1, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{0, -1, 1}, glm.Vec3{0, 1, 1}, 0.5, 0.5, 1, glm.Vec3{0, 0, 0}, glm.Vec3{0, 0, 1}},
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{0.5, -1, 1}, glm.Vec3{0.5, 3, 1}, 0.5, 0.25, 1, glm.Vec3{0.5, 0, 0}, glm.Vec3{0.5, 0, 1}},
+		// Clamped at the ends of the second segment.
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{0.5, 2, 1}, glm.Vec3{0.5, 3, 1}, 0.5, 0, 5, glm.Vec3{0.5, 0, 0}, glm.Vec3{0.5, 2, 1}},
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{0.5, -3, 1}, glm.Vec3{0.5, -2, 1}, 0.5, 1, 5, glm.Vec3{0.5, 0, 0}, glm.Vec3{0.5, -2, 1}},
+		// Clamped at the end of the first segment.
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{3, -1, 1}, glm.Vec3{3, 1, 1}, 1, 0.5, 5, glm.Vec3{1, 0, 0}, glm.Vec3{3, 0, 1}},
+		// Parallel, overlapping and not.
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{0.5, 1, 0}, glm.Vec3{2, 1, 0}, 0.5, 0, 1, glm.Vec3{0.5, 0, 0}, glm.Vec3{0.5, 1, 0}},
+		{glm.Vec3{0, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{2, 1, 0}, glm.Vec3{3, 1, 0}, 1, 0, 2, glm.Vec3{1, 0, 0}, glm.Vec3{2, 1, 0}},
+		// Degenerate segments.
+		{glm.Vec3{1, 2, 3}, glm.Vec3{1, 2, 3}, glm.Vec3{1, 2, 5}, glm.Vec3{1, 2, 5}, 0, 0, 4, glm.Vec3{1, 2, 3}, glm.Vec3{1, 2, 5}},
+		{glm.Vec3{0, 1, 0}, glm.Vec3{0, 1, 0}, glm.Vec3{-1, 0, 0}, glm.Vec3{1, 0, 0}, 0, 0.5, 1, glm.Vec3{0, 1, 0}, glm.Vec3{0, 0, 0}},
+		{glm.Vec3{5, 1, 0}, glm.Vec3{5, 1, 0}, glm.Vec3{-1, 0, 0}, glm.Vec3{1, 0, 0}, 0, 1, 17, glm.Vec3{5, 1, 0}, glm.Vec3{1, 0, 0}},
+		{glm.Vec3{-1, 0, 0}, glm.Vec3{1, 0, 0}, glm.Vec3{3, 2, 0}, glm.Vec3{3, 2, 0}, 1, 0, 8, glm.Vec3{1, 0, 0}, glm.Vec3{3, 2, 0}},
+	}
+
+	for i, test := range tests {
+		s, tt, u, c1, c2 := ClosestPointSegmentSegment(&test.p1, &test.q1, &test.p2, &test.q2)
+		if math.Abs(s-test.s) > 1e-5 || math.Abs(tt-test.t) > 1e-5 || math.Abs(u-test.u) > 1e-4 {
+			t.Errorf("[%d] s, t, u = %f, %f, %f, want %f, %f, %f", i, s, tt, u, test.s, test.t, test.u)
+		}
+		if d1, d2 := c1.Sub(&test.c1), c2.Sub(&test.c2); d1.Len() > 1e-5 || d2.Len() > 1e-5 {
+			t.Errorf("[%d] c1, c2 = %v, %v, want %v, %v", i, c1, c2, test.c1, test.c2)
+		}
+	}
+}
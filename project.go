@@ -49,6 +49,184 @@ func Frustum(left, right, bottom, top, near, far float32) Mat4 {
 	}
 }
 
+// DepthRange is the range of normalized device depth, z/w in clip space, a
+// projection maps the near and far planes to.
+type DepthRange int
+
+// The depth ranges of the different graphics APIs.
+const (
+	// DepthNegativeOneToOne maps near to -1 and far to 1, the OpenGL
+	// convention.
+	DepthNegativeOneToOne DepthRange = iota
+
+	// DepthZeroToOne maps near to 0 and far to 1, the Vulkan, Direct3D and
+	// Metal convention.
+	DepthZeroToOne
+
+	// DepthReverseZ maps near to 1 and far to 0. It distributes the precision
+	// of floating point depth buffers much more evenly.
+	DepthReverseZ
+)
+
+// NDC returns the normalized device depths the near and far planes are mapped
+// to.
+func (d DepthRange) NDC() (near, far float32) {
+	switch d {
+	case DepthZeroToOne:
+		return 0, 1
+	case DepthReverseZ:
+		return 1, 0
+	default:
+		return -1, 1
+	}
+}
+
+// OrthoDepth is like Ortho for the depth range depth.
+func OrthoDepth(left, right, bottom, top, near, far float32, depth DepthRange) Mat4 {
+	m := Ortho(left, right, bottom, top, near, far)
+	fmn := 1 / (far - near)
+	switch depth {
+	case DepthZeroToOne:
+		m[10], m[14] = -fmn, -near*fmn
+	case DepthReverseZ:
+		m[10], m[14] = fmn, far*fmn
+	}
+	return m
+}
+
+// FrustumDepth is like Frustum for the depth range depth. far can be
+// math.Inf(1) for a projection without far plane.
+func FrustumDepth(left, right, bottom, top, near, far float32, depth DepthRange) Mat4 {
+	rml, tmb := 1/(right-left), 1/(top-bottom)
+	A, B := (right+left)*rml, (top+bottom)*tmb
+	C, D := perspectiveDepth(near, far, depth)
+
+	return Mat4{
+		(2 * near) * rml, 0, 0, 0,
+		0, (2 * near) * tmb, 0, 0,
+		A, B, C, -1,
+		0, 0, D, 0,
+	}
+}
+
+// PerspectiveDepth is like Perspective for the depth range depth. far can be
+// math.Inf(1) for a projection without far plane.
+func PerspectiveDepth(fovy, aspect, near, far float32, depth DepthRange) Mat4 {
+	top := near * math.Tan(fovy/2)
+	right := top * aspect
+	return FrustumDepth(-right, right, -top, top, near, far, depth)
+}
+
+// PerspectiveInfinite returns a perspective projection without far plane for
+// the depth range depth. Combined with DepthReverseZ it gives the best depth
+// precision.
+func PerspectiveInfinite(fovy, aspect, near float32, depth DepthRange) Mat4 {
+	return PerspectiveDepth(fovy, aspect, near, math.Inf(1), depth)
+}
+
+// PerspectiveOffAxis returns an asymmetric perspective projection given the
+// tangents of the angles between the view direction and each side of the
+// frustum, like the field of view of the eyes of VR headsets. Angles to the
+// left and down are negative. far can be math.Inf(1) for a projection without
+// far plane.
+func PerspectiveOffAxis(tanLeft, tanRight, tanDown, tanUp, near, far float32, depth DepthRange) Mat4 {
+	return FrustumDepth(tanLeft*near, tanRight*near, tanDown*near, tanUp*near, near, far, depth)
+}
+
+// perspectiveDepth returns the coefficients C and D of a perspective
+// projection such that (C*z+D)/-z is the depth of view space z.
+func perspectiveDepth(near, far float32, depth DepthRange) (C, D float32) {
+	infinite := math.IsInf(far, 1)
+	switch depth {
+	case DepthZeroToOne:
+		if infinite {
+			return -1, -near
+		}
+		nmf := 1 / (near - far)
+		return far * nmf, far * near * nmf
+	case DepthReverseZ:
+		if infinite {
+			return 0, near
+		}
+		fmn := 1 / (far - near)
+		return near * fmn, far * near * fmn
+	}
+	if infinite {
+		return -1, -2 * near
+	}
+	nmf := 1 / (near - far)
+	return (near + far) * nmf, 2 * far * near * nmf
+}
+
+// ProjectionParameters are the parameters of a projection matrix built by
+// Ortho, Frustum, Perspective or their variants.
+type ProjectionParameters struct {
+	// Perspective is true for a perspective projection, false for an
+	// orthographic one.
+	Perspective bool
+
+	// The extents of the view volume. For a perspective projection they are
+	// measured on the near plane.
+	Left, Right, Bottom, Top float32
+
+	// The distances to the near and far planes. Far is math.Inf(1) for a
+	// projection without far plane.
+	Near, Far float32
+}
+
+// DecomposeProjection recovers the parameters of the projection matrix m,
+// built with the depth range depth. The depth range can't be recovered from
+// the matrix alone.
+func DecomposeProjection(m *Mat4, depth DepthRange) ProjectionParameters {
+	nearDepth, farDepth := depth.NDC()
+	C, D := m[10], m[14]
+
+	var p ProjectionParameters
+	if IsPerspective(m) {
+		// The depth of view space z is -C - D/z.
+		p.Perspective = true
+		p.Near = D / (nearDepth + C)
+		if farDepth+C == 0 {
+			p.Far = math.Inf(1)
+		} else {
+			p.Far = D / (farDepth + C)
+		}
+		p.Left, p.Right = (m[8]-1)*p.Near/m[0], (m[8]+1)*p.Near/m[0]
+		p.Bottom, p.Top = (m[9]-1)*p.Near/m[5], (m[9]+1)*p.Near/m[5]
+		return p
+	}
+
+	// The depth of view space z is C*z + D.
+	p.Near, p.Far = (D-nearDepth)/C, (D-farDepth)/C
+	p.Left, p.Right = (-m[12]-1)/m[0], (-m[12]+1)/m[0]
+	p.Bottom, p.Top = (-m[13]-1)/m[5], (-m[13]+1)/m[5]
+	return p
+}
+
+// IsPerspective returns true if m is a perspective projection, false if it is
+// an orthographic one.
+func IsPerspective(m *Mat4) bool {
+	return m[11] != 0
+}
+
+// Fovy returns the vertical field of view of a perspective projection.
+func (p *ProjectionParameters) Fovy() float32 {
+	return math.Atan(p.Top/p.Near) - math.Atan(p.Bottom/p.Near)
+}
+
+// Aspect returns the ratio of the width to the height of the view volume.
+func (p *ProjectionParameters) Aspect() float32 {
+	return (p.Right - p.Left) / (p.Top - p.Bottom)
+}
+
+// Mat4 returns the projection matrix with the depth range depth.
+func (p *ProjectionParameters) Mat4(depth DepthRange) Mat4 {
+	if p.Perspective {
+		return FrustumDepth(p.Left, p.Right, p.Bottom, p.Top, p.Near, p.Far, depth)
+	}
+	return OrthoDepth(p.Left, p.Right, p.Bottom, p.Top, p.Near, p.Far, depth)
+}
+
 // LookAt returns a Mat4 that represents a camera transform from the given
 // arguments.
 func LookAt(eyeX, eyeY, eyeZ, centerX, centerY, centerZ, upX, upY, upZ float32) Mat4 {
@@ -90,14 +268,25 @@ func LookAtV(eye, center, up *Vec3) Mat4 {
 // Window coordinates are continuous, not discrete, so you won't get exact pixel
 // locations without rounding.
 func Project(obj *Vec3, modelview, projection *Mat4, initialX, initialY, width, height int) Vec3 {
+	return ProjectDepth(obj, modelview, projection, initialX, initialY, width, height, DepthNegativeOneToOne)
+}
+
+// ProjectDepth is like Project for a projection using the depth range depth.
+// The window depth is always in [0, 1], reversed for DepthReverseZ.
+func ProjectDepth(obj *Vec3, modelview, projection *Mat4, initialX, initialY, width, height int, depth DepthRange) Vec3 {
 	obj4 := obj.Vec4(1)
 
 	pm := projection.Mul4(modelview)
 	vpp := pm.Mul4x1(&obj4)
+	over := 1 / vpp[3]
+	z := vpp[2] * over
+	if depth == DepthNegativeOneToOne {
+		z = (z + 1) * 0.5
+	}
 	return Vec3{
-		float32(initialX) + (float32(width)*(vpp[0]+1))*0.5,
-		float32(initialY) + (float32(height)*(vpp[1]+1))*0.5,
-		(vpp[2] + 1) * 0.5,
+		float32(initialX) + (float32(width)*(vpp[0]*over+1))*0.5,
+		float32(initialY) + (float32(height)*(vpp[1]*over+1))*0.5,
+		z,
 	}
 }
 
@@ -107,13 +296,23 @@ func Project(obj *Vec3, modelview, projection *Mat4, initialX, initialY, width,
 // Note that the projection may not be perfect if you use strict pixel locations
 // rather than the exact values given by Project.
 func UnProject(win *Vec3, modelview, projection *Mat4, initialX, initialY, width, height int) Vec3 {
+	return UnProjectDepth(win, modelview, projection, initialX, initialY, width, height, DepthNegativeOneToOne)
+}
+
+// UnProjectDepth is like UnProject for a projection using the depth range
+// depth, the inverse of ProjectDepth.
+func UnProjectDepth(win *Vec3, modelview, projection *Mat4, initialX, initialY, width, height int, depth DepthRange) Vec3 {
 	pm := projection.Mul4(modelview)
 	inv := pm.Inverse()
 
+	z := win[2]
+	if depth == DepthNegativeOneToOne {
+		z = 2*z - 1
+	}
 	obj4 := inv.Mul4x1(&Vec4{
 		(2 * (win[0] - float32(initialX)) / float32(width)) - 1,
 		(2 * (win[1] - float32(initialY)) / float32(height)) - 1,
-		2*win[2] - 1,
+		z,
 		1.0,
 	})
 	obj := obj4.Vec3()
@@ -202,3 +202,161 @@ func TestFrustum(t *testing.T) {
 		}
 	}
 }
+
+func TestProjectionDepth(t *testing.T) {
+	t.Parallel()
+	const near, far = 0.5, 50
+	tests := []struct {
+		Depth               DepthRange
+		NearDepth, FarDepth float32
+	}{
+		{DepthNegativeOneToOne, -1, 1},
+		{DepthZeroToOne, 0, 1},
+		{DepthReverseZ, 1, 0},
+	}
+
+	for i, c := range tests {
+		if n, f := c.Depth.NDC(); n != c.NearDepth || f != c.FarDepth {
+			t.Errorf("[%d] NDC = %f, %f, want %f, %f", i, n, f, c.NearDepth, c.FarDepth)
+		}
+		for j, m := range []Mat4{
+			PerspectiveDepth(DegToRad(60), 1.5, near, far, c.Depth),
+			FrustumDepth(-0.2, 0.4, -0.3, 0.1, near, far, c.Depth),
+			PerspectiveOffAxis(-0.9, 1.1, -1.2, 0.8, near, far, c.Depth),
+			OrthoDepth(-3, 2, -1, 4, near, far, c.Depth),
+		} {
+			for _, z := range []struct{ View, Depth float32 }{{-near, c.NearDepth}, {-far, c.FarDepth}} {
+				clip := m.Mul4x1(&Vec4{0.1, 0.1, z.View, 1})
+				if d := clip[2] / clip[3]; !FloatEqualThreshold(d, z.Depth, 1e-4) && math.Abs(d-z.Depth) > 1e-5 {
+					t.Errorf("[%d][%d] depth at %f = %f, want %f", i, j, z.View, d, z.Depth)
+				}
+			}
+		}
+	}
+}
+
+func TestProjectionDepth_GL(t *testing.T) {
+	t.Parallel()
+	if a, b := OrthoDepth(-3, 2, -1, 4, 1, 10, DepthNegativeOneToOne), Ortho(-3, 2, -1, 4, 1, 10); !a.EqualThreshold(&b, 1e-4) {
+		t.Errorf("OrthoDepth = %v, want %v", a, b)
+	}
+	if a, b := FrustumDepth(-3, 2, -1, 4, 1, 10, DepthNegativeOneToOne), Frustum(-3, 2, -1, 4, 1, 10); !a.EqualThreshold(&b, 1e-4) {
+		t.Errorf("FrustumDepth = %v, want %v", a, b)
+	}
+	if a, b := PerspectiveDepth(DegToRad(45), 4.0/3.0, 0.1, 100, DepthNegativeOneToOne), Perspective(DegToRad(45), 4.0/3.0, 0.1, 100); !a.EqualThreshold(&b, 1e-4) {
+		t.Errorf("PerspectiveDepth = %v, want %v", a, b)
+	}
+	tan := math.Tan(DegToRad(45) / 2)
+	if a, b := PerspectiveOffAxis(-tan*4/3, tan*4/3, -tan, tan, 0.1, 100, DepthZeroToOne), PerspectiveDepth(DegToRad(45), 4.0/3.0, 0.1, 100, DepthZeroToOne); !a.EqualThreshold(&b, 1e-4) {
+		t.Errorf("PerspectiveOffAxis = %v, want %v", a, b)
+	}
+}
+
+func TestPerspectiveInfinite(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Depth               DepthRange
+		NearDepth, FarDepth float32
+	}{
+		{DepthNegativeOneToOne, -1, 1},
+		{DepthZeroToOne, 0, 1},
+		{DepthReverseZ, 1, 0},
+	}
+
+	for i, c := range tests {
+		m := PerspectiveInfinite(DegToRad(60), 1, 0.1, c.Depth)
+		for _, z := range []struct{ View, Depth float32 }{{-0.1, c.NearDepth}, {-1e7, c.FarDepth}} {
+			clip := m.Mul4x1(&Vec4{0, 0, z.View, 1})
+			if d := clip[2] / clip[3]; math.Abs(d-z.Depth) > 1e-4 {
+				t.Errorf("[%d] depth at %f = %f, want %f", i, z.View, d, z.Depth)
+			}
+		}
+		// The depth only grows toward far.
+		var prev float32
+		for n, z := range []float32{-0.2, -1, -10, -100, -1000} {
+			clip := m.Mul4x1(&Vec4{0, 0, z, 1})
+			d := clip[2] / clip[3]
+			if n > 0 && (d-prev)*(c.FarDepth-c.NearDepth) <= 0 {
+				t.Errorf("[%d] depth at %f = %f, not toward far from %f", i, z, d, prev)
+			}
+			prev = d
+		}
+	}
+}
+
+func TestProjectDepth(t *testing.T) {
+	t.Parallel()
+	modelview := LookAtV(&Vec3{1, 2, 10}, &Vec3{0, 0, 0}, &Vec3{0, 1, 0})
+	obj := Vec3{0.5, -1, 2}
+	for i, depth := range []DepthRange{DepthNegativeOneToOne, DepthZeroToOne, DepthReverseZ} {
+		projection := PerspectiveDepth(DegToRad(60), 1.5, 1, 100, depth)
+		win := ProjectDepth(&obj, &modelview, &projection, 10, 20, 300, 200, depth)
+
+		// The projection of the point at the center of the window.
+		center := Vec3{0, 0, 0}
+		if c := ProjectDepth(&center, &modelview, &projection, 10, 20, 300, 200, depth); !FloatEqualThreshold(c[0], 160, 1e-4) || !FloatEqualThreshold(c[1], 120, 1e-4) {
+			t.Errorf("[%d] ProjectDepth(%v) = %v, want the center of the window", i, center, c)
+		}
+		if win[2] < 0 || win[2] > 1 {
+			t.Errorf("[%d] ProjectDepth depth = %f, want in [0, 1]", i, win[2])
+		}
+		if r := UnProjectDepth(&win, &modelview, &projection, 10, 20, 300, 200, depth); !r.EqualThreshold(&obj, 1e-3) {
+			t.Errorf("[%d] UnProjectDepth(%v) = %v, want %v", i, win, r, obj)
+		}
+	}
+}
+
+func TestDecomposeProjection(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Perspective              bool
+		Left, Right, Bottom, Top float32
+		Near, Far                float32
+	}{
+		{true, -1, 1, -1, 1, 1, 2},
+		{true, -0.2, 0.4, -0.3, 0.1, 0.5, 50},
+		{true, -0.1, 0.1, -0.05, 0.05, 0.1, math.Inf(1)},
+		{false, -3, 2, -1, 4, 1, 10},
+		{false, 0, 800, 600, 0, -1, 1},
+	}
+
+	for i, c := range tests {
+		want := ProjectionParameters{c.Perspective, c.Left, c.Right, c.Bottom, c.Top, c.Near, c.Far}
+		for _, depth := range []DepthRange{DepthNegativeOneToOne, DepthZeroToOne, DepthReverseZ} {
+			m := want.Mat4(depth)
+			if IsPerspective(&m) != c.Perspective {
+				t.Errorf("[%d][%d] IsPerspective = %t, want %t", i, depth, IsPerspective(&m), c.Perspective)
+			}
+			p := DecomposeProjection(&m, depth)
+			got := [...]float32{p.Left, p.Right, p.Bottom, p.Top, p.Near}
+			exp := [...]float32{c.Left, c.Right, c.Bottom, c.Top, c.Near}
+			for n := range got {
+				if math.Abs(got[n]-exp[n]) > 1e-4*math.Max(1, math.Abs(exp[n])) {
+					t.Errorf("[%d][%d] DecomposeProjection = %+v, want %+v", i, depth, p, want)
+					break
+				}
+			}
+			if p.Perspective != c.Perspective || (p.Far != c.Far && math.Abs(p.Far-c.Far) > 1e-3*c.Far) {
+				t.Errorf("[%d][%d] DecomposeProjection = %+v, want %+v", i, depth, p, want)
+			}
+		}
+	}
+}
+
+func TestProjectionParameters(t *testing.T) {
+	t.Parallel()
+	m := Perspective(DegToRad(45), 4.0/3.0, 0.1, 100)
+	p := DecomposeProjection(&m, DepthNegativeOneToOne)
+	if fovy := p.Fovy(); !FloatEqualThreshold(fovy, DegToRad(45), 1e-4) {
+		t.Errorf("Fovy = %f, want %f", fovy, DegToRad(45))
+	}
+	if aspect := p.Aspect(); !FloatEqualThreshold(aspect, 4.0/3.0, 1e-4) {
+		t.Errorf("Aspect = %f, want %f", aspect, 4.0/3.0)
+	}
+	if !FloatEqualThreshold(p.Near, 0.1, 1e-4) || !FloatEqualThreshold(p.Far, 100, 1e-3) {
+		t.Errorf("Near, Far = %f, %f, want 0.1, 100", p.Near, p.Far)
+	}
+	if r := p.Mat4(DepthNegativeOneToOne); !r.EqualThreshold(&m, 1e-4) {
+		t.Errorf("Mat4 = %v, want %v", r, m)
+	}
+}
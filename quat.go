@@ -375,6 +375,80 @@ func (q1 *Quat) Mat3() Mat3 {
 	}
 }
 
+// AxisAngle returns the angle and the normalized axis of the rotation
+// represented by the normalized quaternion, with angle in [0, pi]. The axis is
+// {1, 0, 0} for the identity.
+func (q1 *Quat) AxisAngle() (angle float32, axis Vec3) {
+	q := *q1
+	if q.W < 0 {
+		q = Quat{-q.W, q.V.Mul(-1)}
+	}
+	l := q.V.Len()
+	if l == 0 {
+		return 0, Vec3{1, 0, 0}
+	}
+	return 2 * math.Atan2(l, q.W), q.V.Mul(1 / l)
+}
+
+// SwingTwist decomposes the rotation represented by the normalized quaternion
+// in a twist around axis followed by a swing around an axis perpendicular to
+// axis, so q1 = swing * twist. axis doesn't need to be normalized.
+//
+// If the rotation turns axis to its opposite the twist is ambiguous, it is
+// then the identity.
+func (q1 *Quat) SwingTwist(axis *Vec3) (swing, twist Quat) {
+	// The twist is the projection of the rotation on axis.
+	l2 := axis.Len2()
+	if l2 == 0 {
+		return *q1, QuatIdent()
+	}
+	twist = Quat{q1.W, axis.Mul(q1.V.Dot(axis) / l2)}
+	if n := twist.Len(); n < 1e-6 {
+		twist = QuatIdent()
+	} else {
+		twist.ScaleWith(1 / n)
+	}
+	conj := twist.Conjugated()
+	swing = q1.Mul(&conj)
+	return swing, twist
+}
+
+// Exp returns the exponential of the quaternion. It is the inverse of Log, for
+// a quaternion {0, axis*angle/2} it returns QuatRotate(angle, axis).
+func (q1 *Quat) Exp() Quat {
+	e := math.Exp(q1.W)
+	l := q1.V.Len()
+	if l == 0 {
+		return Quat{e, Vec3{}}
+	}
+	s, c := math.Sincos(l)
+	return Quat{e * c, q1.V.Mul(e * s / l)}
+}
+
+// Log returns the natural logarithm of the quaternion. For a rotation
+// QuatRotate(angle, axis), with angle in [0, pi], it returns
+// {0, axis*angle/2}.
+func (q1 *Quat) Log() Quat {
+	l := q1.V.Len()
+	w := math.Log(q1.Len())
+	if l == 0 {
+		// Any axis works for negative real quaternions.
+		if q1.W < 0 {
+			return Quat{w, Vec3{math.Pi, 0, 0}}
+		}
+		return Quat{w, Vec3{}}
+	}
+	return Quat{w, q1.V.Mul(math.Atan2(l, q1.W) / l)}
+}
+
+// Pow returns the quaternion raised to the power t. For a rotation it is the
+// same rotation with its angle multiplied by t.
+func (q1 *Quat) Pow(t float32) Quat {
+	l := q1.Log()
+	l = l.Scale(t)
+	return l.Exp()
+}
+
 // Dot returns the dot product between two quaternions.
 func (q1 *Quat) Dot(q2 *Quat) float32 {
 	return q1.W*q2.W + q1.V[0]*q2.V[0] + q1.V[1]*q2.V[1] + q1.V[2]*q2.V[2]
@@ -465,6 +539,169 @@ func QuatNlerp(q1, q2 *Quat, amount float32) Quat {
 	return l.Normalized()
 }
 
+// QuatAverage returns the weighted average of the rotations qs, using
+// Markley's method: the average is the eigenvector of the largest eigenvalue of
+// the sum of weights[n]*qs[n]*qs[n]ᵀ, seen as 4x4 matrices. Unlike QuatNlerp it
+// handles any number of rotations, however spread, and q and -q are the same
+// rotation. If weights is nil every rotation has the same weight. The result is
+// in the hemisphere of qs[0], it is the identity if qs is empty.
+func QuatAverage(qs []Quat, weights []float32) Quat {
+	if len(qs) == 0 {
+		return QuatIdent()
+	}
+
+	var m [4][4]float32
+	for n := range qs {
+		w := float32(1)
+		if weights != nil {
+			w = weights[n]
+		}
+		v := [4]float32{qs[n].W, qs[n].V[0], qs[n].V[1], qs[n].V[2]}
+		for i := range m {
+			for j := range m[i] {
+				m[i][j] += w * v[i] * v[j]
+			}
+		}
+	}
+
+	v := largestEigenvector(&m)
+	q := Quat{v[0], Vec3{v[1], v[2], v[3]}}
+	q.Normalize()
+	if q.Dot(&qs[0]) < 0 {
+		q = q.Scale(-1)
+	}
+	return q
+}
+
+// largestEigenvector returns the eigenvector of the largest eigenvalue of the
+// symmetric matrix m, found with the Jacobi eigenvalue algorithm. m is
+// destroyed.
+func largestEigenvector(m *[4][4]float32) [4]float32 {
+	// The columns of v are the eigenvectors.
+	v := [4][4]float32{{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, 1}}
+	for sweep := 0; sweep < 32; sweep++ {
+		var off, diag float32
+		for i := range m {
+			diag += math.Abs(m[i][i])
+			for j := i + 1; j < 4; j++ {
+				off += math.Abs(m[i][j])
+			}
+		}
+		if off <= 1e-9*diag {
+			break
+		}
+
+		// Rotate the rows and columns p and q to zero m[p][q].
+		for p := 0; p < 4; p++ {
+			for q := p + 1; q < 4; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < 4; k++ {
+					m[k][p], m[k][q] = c*m[k][p]-s*m[k][q], s*m[k][p]+c*m[k][q]
+				}
+				for k := 0; k < 4; k++ {
+					m[p][k], m[q][k] = c*m[p][k]-s*m[q][k], s*m[p][k]+c*m[q][k]
+					v[k][p], v[k][q] = c*v[k][p]-s*v[k][q], s*v[k][p]+c*v[k][q]
+				}
+			}
+		}
+	}
+
+	largest := 0
+	for i := 1; i < 4; i++ {
+		if m[i][i] > m[largest][largest] {
+			largest = i
+		}
+	}
+	return [4]float32{v[0][largest], v[1][largest], v[2][largest], v[3][largest]}
+}
+
+// QuatSquad is *S*pherical *Qua*drangle interpolation between q1 and q2, with
+// the control points s1 and s2, usually from QuatSquadControl. It is the
+// quaternion equivalent of a cubic Bezier curve, and used through several keys
+// its orientations are C1 continuous, unlike with QuatSlerp.
+//
+// Like with QuatSlerp the path isn't the shortest if q1 and q2 are in
+// different hemispheres, q2 should be negated if q1.Dot(q2) < 0.
+func QuatSquad(q1, q2, s1, s2 *Quat, amount float32) Quat {
+	q := QuatSlerp(q1, q2, amount)
+	s := QuatSlerp(s1, s2, amount)
+	return QuatSlerp(&q, &s, 2*amount*(1-amount))
+}
+
+// QuatSquadControl returns the control point of QuatSquad at the key q of a
+// curve, between the keys prev and next. The tangent at q is the average of
+// the directions to prev and next, like for Catmull-Rom splines. The keys must
+// be normalized, prev and next are negated if they aren't in the hemisphere of
+// q.
+func QuatSquadControl(prev, q, next *Quat) Quat {
+	p, n := *prev, *next
+	if q.Dot(&p) < 0 {
+		p = p.Scale(-1)
+	}
+	if q.Dot(&n) < 0 {
+		n = n.Scale(-1)
+	}
+
+	inv := q.Conjugated()
+	toNext, toPrev := inv.Mul(&n), inv.Mul(&p)
+	logNext, logPrev := toNext.Log(), toPrev.Log()
+	sum := logNext.Add(&logPrev)
+	e := sum.Scale(-0.25)
+	e = e.Exp()
+	return q.Mul(&e)
+}
+
+// QuatSpline interpolates the orientations of keys with QuatSquad, so the
+// curve goes through the keys with C1 continuous orientations. amount goes from
+// 0 at the first key to len(keys)-1 at the last one, it is clamped to that
+// range. Keys in different hemispheres are handled, the curve always takes the
+// shortest path between 2 keys.
+func QuatSpline(keys []Quat, amount float32) Quat {
+	switch len(keys) {
+	case 0:
+		return QuatIdent()
+	case 1:
+		return keys[0].Normalized()
+	}
+
+	amount = Clamp(amount, 0, float32(len(keys)-1))
+	n := int(math.Floor(amount))
+	if n == len(keys)-1 {
+		n--
+	}
+
+	// The keys around the segment [n, n+1], in the same hemisphere as their
+	// neighbour. The first and last keys are duplicated.
+	k := [4]Quat{keys[n], keys[n], keys[n+1], keys[n+1]}
+	if n > 0 {
+		k[0] = keys[n-1]
+	}
+	if n+2 < len(keys) {
+		k[3] = keys[n+2]
+	}
+	for i := range k {
+		k[i].Normalize()
+	}
+	for _, i := range [3][2]int{{1, 0}, {1, 2}, {2, 3}} {
+		if k[i[0]].Dot(&k[i[1]]) < 0 {
+			k[i[1]] = k[i[1]].Scale(-1)
+		}
+	}
+
+	s1 := QuatSquadControl(&k[0], &k[1], &k[2])
+	s2 := QuatSquadControl(&k[1], &k[2], &k[3])
+	return QuatSquad(&k[1], &k[2], &s1, &s2, amount-float32(n))
+}
+
 // AnglesToQuat performs a rotation in the specified order. If the order is not
 // a valid RotationOrder, this function will panic.
 //
@@ -562,6 +799,105 @@ func AnglesToQuat(angle1, angle2, angle3 float32, order RotationOrder) Quat {
 	return ret
 }
 
+// QuatToAngles is the inverse of AnglesToQuat, it returns the angles of the
+// rotation q around the axes of order. q must be normalized. If the order is
+// not a valid RotationOrder, this function will panic.
+//
+// See Mat3ToAngles for the range of the angles and gimbal lock.
+func QuatToAngles(q *Quat, order RotationOrder) (angle1, angle2, angle3 float32) {
+	m := q.Mat3()
+	return Mat3ToAngles(&m, order)
+}
+
+// gimbalLockEpsilon is the value under which the cosine of the middle angle of
+// Tait-Bryan angles, or the sine for proper Euler angles, is considered 0.
+const gimbalLockEpsilon = 1e-6
+
+// Mat3ToAngles returns the angles of the pure rotation matrix m around the axes
+// of order, such that AnglesToQuat(angle1, angle2, angle3, order) is the same
+// rotation. If the order is not a valid RotationOrder, this function will
+// panic.
+//
+// angle1 and angle3 are in [-pi, pi]. angle2 is in [-pi/2, pi/2] for
+// Tait-Bryan orders, like XYZ, and in [0, pi] for proper Euler orders, like
+// XYX. At the limits of angle2, in gimbal lock, the first and third axes are
+// aligned and only the sum or difference of angle1 and angle3 is known, angle3
+// is then 0.
+func Mat3ToAngles(m *Mat3, order RotationOrder) (angle1, angle2, angle3 float32) {
+	i, j, l := rotationOrderAxes(order)
+	k := 3 - i - j
+
+	// e is 1 if i, j, k is an even permutation of the axes, like XYZ.
+	e := float32(1)
+	if j != (i+1)%3 {
+		e = -1
+	}
+	r := func(row, col int) float32 {
+		return m[col*3+row]
+	}
+
+	if i == l {
+		// m = Ri(angle1) * Rj(angle2) * Ri(angle3)
+		s := math.Sqrt(r(i, j)*r(i, j) + r(i, k)*r(i, k))
+		angle2 = math.Atan2(s, r(i, i))
+		if s < gimbalLockEpsilon {
+			return math.Atan2(e*r(k, j), r(j, j)), angle2, 0
+		}
+		angle1 = math.Atan2(r(j, i), -e*r(k, i))
+
+		// Ri(-angle1)*m = Rj(angle2)*Ri(angle3), whose row j only depends on
+		// angle3. This stays accurate when angle2 is close to the limits.
+		s1, c1 := math.Sincos(angle1)
+		angle3 = math.Atan2(-e*(c1*r(j, k)+e*s1*r(k, k)), c1*r(j, j)+e*s1*r(k, j))
+		return
+	}
+
+	// m = Ri(angle1) * Rj(angle2) * Rk(angle3)
+	c := math.Sqrt(r(i, i)*r(i, i) + r(i, j)*r(i, j))
+	angle2 = math.Atan2(e*r(i, k), c)
+	if c < gimbalLockEpsilon {
+		return math.Atan2(e*r(k, j), r(j, j)), angle2, 0
+	}
+	angle1 = math.Atan2(-e*r(j, k), r(k, k))
+
+	// Ri(-angle1)*m = Rj(angle2)*Rk(angle3), whose row j only depends on
+	// angle3.
+	s1, c1 := math.Sincos(angle1)
+	angle3 = math.Atan2(e*(c1*r(j, i)+e*s1*r(k, i)), c1*r(j, j)+e*s1*r(k, j))
+	return
+}
+
+// rotationOrderAxes returns the axes of order, 0 for X, 1 for Y and 2 for Z.
+func rotationOrderAxes(order RotationOrder) (first, second, third int) {
+	switch order {
+	case XYX:
+		return 0, 1, 0
+	case XYZ:
+		return 0, 1, 2
+	case XZX:
+		return 0, 2, 0
+	case XZY:
+		return 0, 2, 1
+	case YXY:
+		return 1, 0, 1
+	case YXZ:
+		return 1, 0, 2
+	case YZY:
+		return 1, 2, 1
+	case YZX:
+		return 1, 2, 0
+	case ZYZ:
+		return 2, 1, 2
+	case ZYX:
+		return 2, 1, 0
+	case ZXZ:
+		return 2, 0, 2
+	case ZXY:
+		return 2, 0, 1
+	}
+	panic("Unsupported rotation order")
+}
+
 // Mat4ToQuat converts a pure rotation matrix into a quaternion
 func Mat4ToQuat(m *Mat4) Quat {
 	// http://www.euclideanspace.com/maths/geometry/rotations/conversions/matrixToQuaternion/index.htm
@@ -614,6 +950,28 @@ func Mat4ToQuat(m *Mat4) Quat {
 	}
 }
 
+// Mat3ToQuat converts a pure rotation matrix into a quaternion. It uses
+// Shepperd's method, which divides by the largest of the possible
+// denominators so it stays accurate for every rotation.
+func Mat3ToQuat(m *Mat3) Quat {
+	// m[col*3+row]
+	tr := m[0] + m[4] + m[8]
+	switch {
+	case tr >= m[0] && tr >= m[4] && tr >= m[8]:
+		s := 2 * math.Sqrt(1+tr)
+		return Quat{0.25 * s, Vec3{(m[5] - m[7]) / s, (m[6] - m[2]) / s, (m[1] - m[3]) / s}}
+	case m[0] >= m[4] && m[0] >= m[8]:
+		s := 2 * math.Sqrt(1+m[0]-m[4]-m[8])
+		return Quat{(m[5] - m[7]) / s, Vec3{0.25 * s, (m[3] + m[1]) / s, (m[6] + m[2]) / s}}
+	case m[4] >= m[8]:
+		s := 2 * math.Sqrt(1+m[4]-m[0]-m[8])
+		return Quat{(m[6] - m[2]) / s, Vec3{(m[3] + m[1]) / s, 0.25 * s, (m[7] + m[5]) / s}}
+	default:
+		s := 2 * math.Sqrt(1+m[8]-m[0]-m[4])
+		return Quat{(m[1] - m[3]) / s, Vec3{(m[6] + m[2]) / s, (m[7] + m[5]) / s, 0.25 * s}}
+	}
+}
+
 // QuatLookAtV creates a rotation from an eye vector to a center vector
 //
 // It assumes the front of the rotated object at Z- and up at Y+
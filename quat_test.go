@@ -276,6 +276,37 @@ func TestMat4ToQuat(t *testing.T) {
 	}
 }
 
+func TestMat3ToQuat(t *testing.T) {
+	t.Parallel()
+	tests := []Quat{
+		QuatIdent(),
+		QuatRotate(0.5, &Vec3{0, 1, 0}),
+		// Rotations of about pi take every branch.
+		QuatRotate(math.Pi, &Vec3{1, 0, 0}),
+		QuatRotate(math.Pi, &Vec3{0, 1, 0}),
+		QuatRotate(math.Pi, &Vec3{0, 0, 1}),
+		QuatRotate(3, &Vec3{0.6, 0, 0.8}),
+		QuatRotate(-3, &Vec3{0, 0.8, -0.6}),
+		{W: 0.5, V: Vec3{0.5, -0.5, 0.5}},
+	}
+	for _, test := range quatTests {
+		tests = append(tests, test.q1.Normalized())
+	}
+
+	for i, q := range tests {
+		m := q.Mat3()
+		if r := Mat3ToQuat(&m); !r.OrientationEqualThreshold(&q, 1e-6) {
+			t.Errorf("[%d] Mat3ToQuat = %v, want %v", i, r, q)
+		}
+	}
+	for i, test := range quatTests {
+		q := test.q1.Normalized()
+		if r := Mat3ToQuat(&test.mat3); !r.OrientationEqualThreshold(&q, 1e-6) {
+			t.Errorf("[%d] Mat3ToQuat(mat3) = %v, want %v", i, r, q)
+		}
+	}
+}
+
 func TestQuatRotate(t *testing.T) {
 	t.Parallel()
 	qiden := QuatIdent()
@@ -1116,3 +1147,364 @@ func TestQuat_Mat4(t *testing.T) {
 		}
 	}
 }
+
+// rotationOrders are all the valid rotation orders.
+var rotationOrders = []RotationOrder{XYX, XYZ, XZX, XZY, YXY, YXZ, YZY, YZX, ZYZ, ZYX, ZXZ, ZXY}
+
+// rotationOrderQuat returns the product of the rotations around the axes of
+// order.
+func rotationOrderQuat(angle1, angle2, angle3 float32, order RotationOrder) Quat {
+	first, second, third := rotationOrderAxes(order)
+	var axes [3]Vec3
+	axes[0][first], axes[1][second], axes[2][third] = 1, 1, 1
+	q1, q2, q3 := QuatRotate(angle1, &axes[0]), QuatRotate(angle2, &axes[1]), QuatRotate(angle3, &axes[2])
+	q := q1.Mul(&q2)
+	return q.Mul(&q3)
+}
+
+func TestAnglesToQuat_Orders(t *testing.T) {
+	t.Parallel()
+	for _, order := range rotationOrders {
+		q := AnglesToQuat(0.3, -0.7, 1.1, order)
+		expected := rotationOrderQuat(0.3, -0.7, 1.1, order)
+		if !q.OrientationEqualThreshold(&expected, 1e-5) {
+			t.Errorf("[%d] AnglesToQuat = %v, want %v", order, q, expected)
+		}
+	}
+}
+
+func TestQuatToAngles(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		angles [3]float32
+		// lock is true if the angles are in gimbal lock for Tait-Bryan orders,
+		// or proper Euler orders.
+		taitBryanLock, properLock bool
+	}{
+		{[3]float32{0.3, 0.7, 1.1}, false, false},
+		{[3]float32{-2.5, 1.2, -0.4}, false, false},
+		{[3]float32{3, 0.1, -3}, false, false},
+		{[3]float32{0.3, math.Pi / 2, 1.1}, true, false},
+		{[3]float32{0.3, -math.Pi / 2, 1.1}, true, false},
+		{[3]float32{0.3, math.Pi/2 - 1e-3, 1.1}, false, false},
+		{[3]float32{0.3, 0, 1.1}, false, true},
+		{[3]float32{0.3, math.Pi, 1.1}, false, true},
+		{[3]float32{-0.3, 1e-3, 1.1}, false, false},
+	}
+
+	for i, test := range tests {
+		for _, order := range rotationOrders {
+			first, _, third := rotationOrderAxes(order)
+			proper := first == third
+			angles := test.angles
+
+			q := AnglesToQuat(angles[0], angles[1], angles[2], order)
+			m := q.Mat3()
+			a1, a2, a3 := QuatToAngles(&q, order)
+			b1, b2, b3 := Mat3ToAngles(&m, order)
+			if a1 != b1 || a2 != b2 || a3 != b3 {
+				t.Errorf("[%d] order %d, QuatToAngles = %f, %f, %f, Mat3ToAngles = %f, %f, %f", i, order, a1, a2, a3, b1, b2, b3)
+			}
+
+			// The angles give back the same rotation.
+			r := AnglesToQuat(a1, a2, a3, order)
+			if !r.OrientationEqualThreshold(&q, 1e-6) {
+				t.Errorf("[%d] order %d, angles %f, %f, %f give %v, want %v", i, order, a1, a2, a3, r, q)
+			}
+
+			lock := test.taitBryanLock
+			if proper {
+				lock = test.properLock
+			}
+			if lock {
+				if a3 != 0 {
+					t.Errorf("[%d] order %d, angle3 = %f in gimbal lock, want 0", i, order, a3)
+				}
+				continue
+			}
+			// Only the angles in the range of Mat3ToAngles come back.
+			if (proper && angles[1] < 0) || (!proper && angles[1] > math.Pi/2) {
+				continue
+			}
+			if !FloatEqualThreshold(a1, angles[0], 1e-3) || !FloatEqualThreshold(a2, angles[1], 1e-3) || !FloatEqualThreshold(a3, angles[2], 1e-3) {
+				t.Errorf("[%d] order %d, angles = %f, %f, %f, want %f, %f, %f", i, order, a1, a2, a3, angles[0], angles[1], angles[2])
+			}
+		}
+	}
+}
+
+func TestQuat_AxisAngle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		q     Quat
+		angle float32
+		axis  Vec3
+	}{
+		{QuatIdent(), 0, Vec3{1, 0, 0}},
+		{QuatRotate(1, &Vec3{0, 1, 0}), 1, Vec3{0, 1, 0}},
+		{QuatRotate(-1, &Vec3{0, 1, 0}), 1, Vec3{0, -1, 0}},
+		{QuatRotate(3, &Vec3{0.6, 0, 0.8}), 3, Vec3{0.6, 0, 0.8}},
+		// The same rotation with W < 0.
+		{QuatRotate(3*math.Pi/2, &Vec3{0, 0, 1}), math.Pi / 2, Vec3{0, 0, -1}},
+	}
+
+	for i, test := range tests {
+		angle, axis := test.q.AxisAngle()
+		if !FloatEqualThreshold(angle, test.angle, 1e-4) || !axis.EqualThreshold(&test.axis, 1e-4) {
+			t.Errorf("[%d] AxisAngle = %f, %v, want %f, %v", i, angle, axis, test.angle, test.axis)
+		}
+		if r := QuatRotate(angle, &axis); !r.OrientationEqualThreshold(&test.q, 1e-6) {
+			t.Errorf("[%d] QuatRotate(AxisAngle) = %v, want %v", i, r, test.q)
+		}
+	}
+}
+
+func TestQuat_SwingTwist(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		q     Quat
+		axis  Vec3
+		twist Quat
+	}{
+		{QuatIdent(), Vec3{0, 1, 0}, QuatIdent()},
+		{QuatRotate(1, &Vec3{0, 1, 0}), Vec3{0, 1, 0}, QuatRotate(1, &Vec3{0, 1, 0})},
+		{QuatRotate(1, &Vec3{1, 0, 0}), Vec3{0, 1, 0}, QuatIdent()},
+		{QuatRotate(1, &Vec3{0, 1, 0}), Vec3{0, 3, 0}, QuatRotate(1, &Vec3{0, 1, 0})},
+		// The twist is ambiguous when the axis is turned to its opposite.
+		{QuatRotate(math.Pi, &Vec3{1, 0, 0}), Vec3{0, 1, 0}, QuatIdent()},
+		{AnglesToQuat(0.7, 0.4, 0, YZX), Vec3{0, 0, 1}, QuatRotate(0.4, &Vec3{0, 0, 1})},
+		{AnglesToQuat(0.4, 0.7, -0.2, ZYX), Vec3{1, 0, 0}, Quat{}},
+		{Quat{W: 0.5, V: Vec3{0.5, -0.5, 0.5}}, Vec3{1, 2, 3}, Quat{}},
+	}
+
+	for i, test := range tests {
+		swing, twist := test.q.SwingTwist(&test.axis)
+		if test.twist != (Quat{}) && !twist.OrientationEqualThreshold(&test.twist, 1e-6) {
+			t.Errorf("[%d] twist = %v, want %v", i, twist, test.twist)
+		}
+
+		// The product is the rotation, the twist is around the axis and the
+		// swing around a perpendicular axis.
+		if r := swing.Mul(&twist); !r.OrientationEqualThreshold(&test.q, 1e-6) {
+			t.Errorf("[%d] swing*twist = %v, want %v", i, r, test.q)
+		}
+		if l := twist.Len(); !FloatEqualThreshold(l, 1, 1e-4) {
+			t.Errorf("[%d] twist length = %f, want 1", i, l)
+		}
+		axis := test.axis.Normalized()
+		if c := twist.V.Cross(&axis); c.Len() > 1e-4 {
+			t.Errorf("[%d] twist %v isn't around %v", i, twist, test.axis)
+		}
+		if d := swing.V.Dot(&axis); math.Abs(d) > 1e-4 {
+			t.Errorf("[%d] swing %v isn't perpendicular to %v", i, swing, test.axis)
+		}
+	}
+}
+
+func TestQuat_ExpLog(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		q, log Quat
+	}{
+		{QuatIdent(), Quat{}},
+		{QuatRotate(1, &Vec3{0, 1, 0}), Quat{0, Vec3{0, 0.5, 0}}},
+		{QuatRotate(3, &Vec3{0.6, 0, -0.8}), Quat{0, Vec3{0.9, 0, -1.2}}},
+		{Quat{W: -1, V: Vec3{}}, Quat{0, Vec3{math.Pi, 0, 0}}},
+		{Quat{W: 2, V: Vec3{}}, Quat{math.Log(2), Vec3{}}},
+		{Quat{W: 1, V: Vec3{2, 3, 4}}, Quat{}},
+	}
+
+	for i, test := range tests {
+		l := test.q.Log()
+		if test.log != (Quat{}) && !l.EqualThreshold(&test.log, 1e-4) {
+			t.Errorf("[%d] log = %v, want %v", i, l, test.log)
+		}
+		e := l.Exp()
+		if d := e.Sub(&test.q); d.Len() > 1e-4*test.q.Len() {
+			t.Errorf("[%d] exp(log(q)) = %v, want %v", i, e, test.q)
+		}
+	}
+}
+
+func TestQuat_Pow(t *testing.T) {
+	t.Parallel()
+	axis := Vec3{0, 0.6, 0.8}
+	tests := []struct {
+		angle, t float32
+	}{
+		{1, 0},
+		{1, 1},
+		{1, 0.5},
+		{1, 2.5},
+		{-2, 0.3},
+		{3, -1},
+	}
+
+	for i, test := range tests {
+		q := QuatRotate(test.angle, &axis)
+		expected := QuatRotate(test.angle*test.t, &axis)
+		if p := q.Pow(test.t); !p.OrientationEqualThreshold(&expected, 1e-6) {
+			t.Errorf("[%d] pow = %v, want %v", i, p, expected)
+		}
+	}
+}
+
+func TestQuatSquad(t *testing.T) {
+	t.Parallel()
+	q1 := QuatRotate(0.3, &Vec3{1, 0, 0})
+	q2 := AnglesToQuat(0.5, -1, 0.2, ZYX)
+	s1 := AnglesToQuat(0.1, 0.2, 0.3, XYZ)
+	s2 := AnglesToQuat(-0.4, -0.6, 0.3, XYZ)
+
+	for i, test := range []struct {
+		amount   float32
+		expected Quat
+	}{
+		{0, q1},
+		{1, q2},
+	} {
+		if q := QuatSquad(&q1, &q2, &s1, &s2, test.amount); !q.OrientationEqualThreshold(&test.expected, 1e-6) {
+			t.Errorf("[%d] squad = %v, want %v", i, q, test.expected)
+		}
+	}
+
+	// With the keys as control points it is slerp.
+	for i, amount := range []float32{0.2, 0.5, 0.9} {
+		expected := QuatSlerp(&q1, &q2, amount)
+		if q := QuatSquad(&q1, &q2, &q1, &q2, amount); !q.OrientationEqualThreshold(&expected, 1e-6) {
+			t.Errorf("[%d] squad = %v, want %v", i, q, expected)
+		}
+	}
+}
+
+func TestQuatSpline(t *testing.T) {
+	t.Parallel()
+	axis := Vec3{0, 1, 0}
+	keys := []Quat{
+		QuatRotate(0, &axis),
+		QuatRotate(1, &axis),
+		QuatRotate(2, &axis),
+		QuatRotate(3, &axis),
+		QuatRotate(4, &axis),
+	}
+	// The same orientation in the other hemisphere.
+	keys[3] = keys[3].Scale(-1)
+
+	// Around a single axis with constant steps, the spline turns at constant
+	// speed except between the first 2 and last 2 keys.
+	for i, amount := range []float32{-1, 0, 1, 1.5, 2.9, 3, 4, 5} {
+		expected := QuatRotate(Clamp(amount, 0, 4), &axis)
+		if q := QuatSpline(keys, amount); !q.OrientationEqualThreshold(&expected, 1e-6) {
+			t.Errorf("[%d] spline(%f) = %v, want %v", i, amount, q, expected)
+		}
+	}
+
+	keys = []Quat{
+		QuatIdent(),
+		AnglesToQuat(0.5, 0.2, -0.3, XYZ),
+		AnglesToQuat(1.5, -0.4, 0.6, XYZ),
+		AnglesToQuat(1, 1, 1, ZYX),
+	}
+	keys[2] = keys[2].Scale(-1)
+	for n := range keys {
+		if q := QuatSpline(keys, float32(n)); !q.OrientationEqualThreshold(&keys[n], 1e-6) {
+			t.Errorf("[%d] spline = %v, want key %v", n, q, keys[n])
+		}
+	}
+
+	// The angular velocity is the same on both sides of the inner keys.
+	const h = 1e-3
+	for n := 1; n < len(keys)-1; n++ {
+		before, at, after := QuatSpline(keys, float32(n)-h), QuatSpline(keys, float32(n)), QuatSpline(keys, float32(n)+h)
+		if at.Dot(&before) < 0 {
+			before = before.Scale(-1)
+		}
+		if at.Dot(&after) < 0 {
+			after = after.Scale(-1)
+		}
+		inv, invBefore := at.Conjugated(), before.Conjugated()
+		in, out := inv.Mul(&after), invBefore.Mul(&at)
+		in, out = in.Log(), out.Log()
+		if d := in.V.Sub(&out.V); d.Len() > 5e-2*in.V.Len() {
+			t.Errorf("[%d] velocities %v and %v around the key differ", n, out.V, in.V)
+		}
+	}
+
+	if q := QuatSpline(nil, 1); q != QuatIdent() {
+		t.Errorf("spline of no keys = %v, want identity", q)
+	}
+	if q := QuatSpline(keys[1:2], 1); !q.OrientationEqualThreshold(&keys[1], 1e-6) {
+		t.Errorf("spline of 1 key = %v, want %v", q, keys[1])
+	}
+}
+
+func TestQuatAverage(t *testing.T) {
+	t.Parallel()
+	x, y := Vec3{1, 0, 0}, Vec3{0, 1, 0}
+	q := AnglesToQuat(0.3, 1, -2, ZYX)
+	opposite := QuatRotate(1.5, &y)
+	opposite = opposite.Scale(-1)
+	tests := []struct {
+		qs       []Quat
+		weights  []float32
+		expected Quat
+	}{
+		{nil, nil, QuatIdent()},
+		{[]Quat{q}, nil, q},
+		{[]Quat{q, q, q}, []float32{1, 2, 3}, q},
+		{[]Quat{QuatRotate(0.5, &y), QuatRotate(1.5, &y)}, nil, QuatRotate(1, &y)},
+		// q and -q are the same rotation.
+		{[]Quat{QuatRotate(0.5, &y), opposite}, nil, QuatRotate(1, &y)},
+		{[]Quat{QuatRotate(0.5, &y), q}, []float32{1, 0}, QuatRotate(0.5, &y)},
+		// Widely spread.
+		{[]Quat{QuatRotate(1, &x), QuatRotate(-1, &x), QuatRotate(1, &y), QuatRotate(-1, &y)}, nil, QuatIdent()},
+		{[]Quat{QuatRotate(3, &x), QuatRotate(-3, &x)}, nil, QuatRotate(math.Pi, &x)},
+	}
+
+	for i, test := range tests {
+		a := QuatAverage(test.qs, test.weights)
+		if !a.OrientationEqualThreshold(&test.expected, 1e-6) {
+			t.Errorf("[%d] average = %v, want %v", i, a, test.expected)
+		}
+		if len(test.qs) > 0 && a.Dot(&test.qs[0]) < 0 {
+			t.Errorf("[%d] average %v isn't in the hemisphere of %v", i, a, test.qs[0])
+		}
+	}
+}
+
+func TestQuatAverage_Random(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	randomQuat := func() Quat {
+		q := Quat{r.Float32()*2 - 1, Vec3{r.Float32()*2 - 1, r.Float32()*2 - 1, r.Float32()*2 - 1}}
+		return q.Normalized()
+	}
+
+	// The average maximizes the weighted sum of the squared dot products with
+	// the rotations.
+	for i := 0; i < 20; i++ {
+		qs := make([]Quat, 2+r.Intn(8))
+		weights := make([]float32, len(qs))
+		for n := range qs {
+			qs[n], weights[n] = randomQuat(), r.Float32()
+		}
+		score := func(q *Quat) float32 {
+			var s float32
+			for n := range qs {
+				d := q.Dot(&qs[n])
+				s += weights[n] * d * d
+			}
+			return s
+		}
+
+		a := QuatAverage(qs, weights)
+		best := score(&a)
+		for n := 0; n < 1000; n++ {
+			q := randomQuat()
+			if s := score(&q); s > best+1e-5 {
+				t.Errorf("[%d] %v scores %f, better than the average %v at %f", i, q, s, a, best)
+				break
+			}
+		}
+	}
+}
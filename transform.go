@@ -59,6 +59,37 @@ func (t *Transform) Concatenate(t2 *Transform) {
 	((*Mat4)(t)).Mul4With((*Mat4)(t2))
 }
 
+// SetCompose sets the transform to the scale followed by the rotation and the
+// translation, see Compose.
+func (t *Transform) SetCompose(translation *Vec3, rotation *Quat, scale *Vec3) {
+	*t = Transform(Compose(translation, rotation, scale))
+}
+
+// Decompose splits the transform in a translation, a rotation and a scale, see
+// Decompose.
+func (t *Transform) Decompose() (translation Vec3, rotation Quat, scale Vec3, ok bool) {
+	return Decompose((*Mat4)(t))
+}
+
+// TransformLerp interpolates between the transforms a and b, 0 giving a and 1
+// giving b, see Mat3x4Lerp. Only the affine part of the transforms is used.
+func TransformLerp(a, b *Transform, t float32) Transform {
+	ma, mb := ((*Mat4)(a)).Mat3x4(), ((*Mat4)(b)).Mat3x4()
+	m := Mat3x4Lerp(&ma, &mb, t)
+	return Transform(m.Mat4())
+}
+
+// TransformBlend returns the weighted average of the transforms ts, see
+// Mat3x4Blend. Only the affine part of the transforms is used.
+func TransformBlend(ts []Transform, weights []float32) Transform {
+	ms := make([]Mat3x4, len(ts))
+	for n := range ts {
+		ms[n] = ((*Mat4)(&ts[n])).Mat3x4()
+	}
+	m := Mat3x4Blend(ms, weights)
+	return Transform(m.Mat4())
+}
+
 // LocalToWorld transform a given point and returns the world point that this
 // transform generates.
 func (t *Transform) LocalToWorld(v *Vec3) Vec3 {
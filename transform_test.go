@@ -1,6 +1,7 @@
 package glm
 
 import (
+	"github.com/EngoEngine/math"
 	"testing"
 )
 
@@ -98,3 +99,41 @@ func TestSpecial(t *testing.T) {
 	t.Errorf("%s", local.String())
 }
 */
+
+func TestTransform_Compose(t *testing.T) {
+	t.Parallel()
+	translation, scale := Vec3{1, -2, 3}, Vec3{2, 2, 0.5}
+	rotation := QuatRotate(1, &Vec3{0, 0.6, 0.8})
+
+	var tr Transform
+	tr.SetCompose(&translation, &rotation, &scale)
+	if m, expected := tr.Mat4(), Compose(&translation, &rotation, &scale); m != expected {
+		t.Errorf("SetCompose =\n%swant\n%s", m.String(), expected.String())
+	}
+
+	// A translation of 1 on x in the scaled and rotated space.
+	tr.TranslateVec3(&Vec3{1, 0, 0})
+	translation = rotation.Rotate(&Vec3{2, 0, 0})
+	translation.AddWith(&Vec3{1, -2, 3})
+	tt, r, s, ok := tr.Decompose()
+	if !ok || !near(tt[:], translation[:], 1e-4) || !r.OrientationEqualThreshold(&rotation, 1e-5) || !near(s[:], scale[:], 1e-4) {
+		t.Errorf("Decompose = %v, %v, %v, %t, want %v, %v, %v, true", tt, r, s, ok, translation, rotation, scale)
+	}
+}
+
+func TestTransformLerp(t *testing.T) {
+	t.Parallel()
+	var a, b Transform
+	a.SetCompose(&Vec3{1, 2, 3}, &Quat{W: 1}, &Vec3{1, 1, 1})
+	rotation := QuatRotate(math.Pi/2, &Vec3{1, 0, 0})
+	b.SetCompose(&Vec3{3, 2, 1}, &rotation, &Vec3{4, 4, 4})
+
+	half := QuatRotate(math.Pi/4, &Vec3{1, 0, 0})
+	expected := Compose(&Vec3{2, 2, 2}, &half, &Vec3{2, 2, 2})
+	if tr := TransformLerp(&a, &b, 0.5); !near(tr[:], expected[:], 1e-4) {
+		t.Errorf("TransformLerp =\n%swant\n%s", tr.String(), expected.String())
+	}
+	if tr := TransformBlend([]Transform{a, b}, []float32{1, 1}); !near(tr[:], expected[:], 1e-4) {
+		t.Errorf("TransformBlend =\n%swant\n%s", tr.String(), expected.String())
+	}
+}
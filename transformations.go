@@ -204,6 +204,206 @@ func ExtractMaxScale(m *Mat4) float32 {
 	return math.Sqrt(math.Max(scaleX, math.Max(scaleY, scaleZ)))
 }
 
+// Decompose splits the homogeneous matrix m in a translation, a rotation and a
+// scale, such that m = Compose(translation, rotation, scale). A reflection is
+// a negative scale on x. The direction of an axis with a scale of 0 is lost,
+// the rotation is then one of those giving m. It returns false if m can't be
+// represented this way: if it has a perspective or a shear, or if all its
+// scales are 0. The values returned are then an approximation of m.
+func Decompose(m *Mat4) (translation Vec3, rotation Quat, scale Vec3, ok bool) {
+	const epsilon = 1e-4
+
+	affine := m.Mat3x4()
+	if m[15] != 0 && m[15] != 1 {
+		affine = affine.Mul(1 / m[15])
+	}
+	translation, rotation, scale, ok = DecomposeMat3x4(&affine)
+	if m[15] == 0 || math.Abs(m[3]) > epsilon || math.Abs(m[7]) > epsilon || math.Abs(m[11]) > epsilon {
+		ok = false
+	}
+	return
+}
+
+// DecomposeMat3x4 is like Decompose for a transform stored in a Mat3x4, with a
+// last row of [0 0 0 1].
+func DecomposeMat3x4(m *Mat3x4) (translation Vec3, rotation Quat, scale Vec3, ok bool) {
+	const epsilon = 1e-4
+
+	translation = Vec3{m[9], m[10], m[11]}
+	axes := [3]Vec3{{m[0], m[1], m[2]}, {m[3], m[4], m[5]}, {m[6], m[7], m[8]}}
+	nonZero := -1
+	for i := range axes {
+		scale[i] = axes[i].Len()
+		if scale[i] != 0 {
+			axes[i].MulWith(1 / scale[i])
+			nonZero = i
+		}
+	}
+	if nonZero < 0 {
+		return translation, QuatIdent(), scale, false
+	}
+
+	// The axes with a scale of 0 are rebuilt perpendicular to the others, any
+	// direction gives the same matrix.
+	for i := range axes {
+		if scale[i] != 0 {
+			continue
+		}
+		axes[i] = axes[(i+1)%3].Cross(&axes[(i+2)%3])
+		if axes[i].Len2() < epsilon {
+			// Another scale is 0, take any perpendicular of the remaining
+			// axis.
+			up := Vec3{1, 0, 0}
+			axes[i] = up.Cross(&axes[nonZero])
+			if axes[i].Len2() < epsilon {
+				up = Vec3{0, 1, 0}
+				axes[i] = up.Cross(&axes[nonZero])
+			}
+		}
+		axes[i].Normalize()
+	}
+
+	// A reflection is a negative scale on x.
+	c := axes[0].Cross(&axes[1])
+	if c.Dot(&axes[2]) < 0 {
+		scale[0] = -scale[0]
+		axes[0].MulWith(-1)
+	}
+
+	// If the axes aren't orthogonal there is a shear, the rotation is the
+	// closest one that keeps the x axis.
+	ok = math.Abs(axes[0].Dot(&axes[1])) <= epsilon &&
+		math.Abs(axes[0].Dot(&axes[2])) <= epsilon &&
+		math.Abs(axes[1].Dot(&axes[2])) <= epsilon
+	if !ok {
+		axes[1].AddScaledVec(-axes[0].Dot(&axes[1]), &axes[0])
+		if axes[1].Len2() == 0 {
+			return translation, QuatIdent(), scale, false
+		}
+		axes[1].Normalize()
+		axes[2] = axes[0].Cross(&axes[1])
+	}
+
+	rot := Mat3FromCols(&axes[0], &axes[1], &axes[2])
+	rotation = Mat3ToQuat(&rot)
+	rotation.Normalize()
+	return
+}
+
+// Compose returns the homogeneous matrix of the scale, followed by the
+// rotation and the translation. rotation must be normalized. It is the inverse
+// of Decompose.
+func Compose(translation *Vec3, rotation *Quat, scale *Vec3) Mat4 {
+	m := ComposeMat3x4(translation, rotation, scale)
+	return m.Mat4()
+}
+
+// ComposeMat3x4 is like Compose for a transform stored in a Mat3x4.
+func ComposeMat3x4(translation *Vec3, rotation *Quat, scale *Vec3) Mat3x4 {
+	r := rotation.Mat3()
+	return Mat3x4{
+		r[0] * scale[0], r[1] * scale[0], r[2] * scale[0],
+		r[3] * scale[1], r[4] * scale[1], r[5] * scale[1],
+		r[6] * scale[2], r[7] * scale[2], r[8] * scale[2],
+		translation[0], translation[1], translation[2],
+	}
+}
+
+// Mat3x4Lerp interpolates between the transforms a and b, 0 giving a and 1
+// giving b. Unlike blending the elements of the matrices, it keeps the
+// rotation and the scale: the translations are interpolated linearly, the
+// rotations with QuatSlerp along the shortest path, and the scales
+// logarithmically so they grow at a constant rate. Transforms with a shear
+// are approximated, see DecomposeMat3x4.
+func Mat3x4Lerp(a, b *Mat3x4, t float32) Mat3x4 {
+	ta, ra, sa, _ := DecomposeMat3x4(a)
+	tb, rb, sb, _ := DecomposeMat3x4(b)
+
+	translation := ta.Mul(1 - t)
+	translation.AddScaledVec(t, &tb)
+	if ra.Dot(&rb) < 0 {
+		rb = rb.Scale(-1)
+	}
+	rotation := QuatSlerp(&ra, &rb, t)
+	var scale Vec3
+	for i := range scale {
+		scale[i] = scaleLerp(sa[i], sb[i], t)
+	}
+	return ComposeMat3x4(&translation, &rotation, &scale)
+}
+
+// scaleLerp interpolates logarithmically between the scales a and b. It falls
+// back to a linear interpolation if they have different signs or one is 0.
+func scaleLerp(a, b, t float32) float32 {
+	if a*b <= 0 {
+		return a + (b-a)*t
+	}
+	s := math.Exp((1-t)*math.Log(math.Abs(a)) + t*math.Log(math.Abs(b)))
+	if a < 0 {
+		return -s
+	}
+	return s
+}
+
+// Mat3x4Blend returns the weighted average of the transforms ms, keeping
+// their rotation and scale like Mat3x4Lerp. The translations are averaged
+// linearly, the rotations with QuatAverage, and the scales geometrically, so
+// a scale of 0 with a non-zero weight gives a scale of 0. The weights are
+// normalized, the result is the identity if they sum to 0. ms and weights must
+// have the same length.
+func Mat3x4Blend(ms []Mat3x4, weights []float32) Mat3x4 {
+	var total float32
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return Ident3x4()
+	}
+	normalized := make([]float32, len(weights))
+	for n, w := range weights {
+		normalized[n] = w / total
+	}
+
+	var translation, logScale, sign Vec3
+	var zero [3]bool
+	rotations := make([]Quat, len(ms))
+	for n := range ms {
+		tr, r, s, _ := DecomposeMat3x4(&ms[n])
+		rotations[n] = r
+		w := normalized[n]
+		if w == 0 {
+			continue
+		}
+		translation.AddScaledVec(w, &tr)
+		for i := range s {
+			// The logarithm of 0 is -Inf, which gives NaN once summed.
+			if s[i] == 0 {
+				zero[i] = true
+				continue
+			}
+			logScale[i] += w * math.Log(math.Abs(s[i]))
+			if s[i] < 0 {
+				sign[i] -= w
+			} else {
+				sign[i] += w
+			}
+		}
+	}
+
+	rotation := QuatAverage(rotations, normalized)
+	var scale Vec3
+	for i := range scale {
+		if zero[i] {
+			continue
+		}
+		scale[i] = math.Exp(logScale[i])
+		if sign[i] < 0 {
+			scale[i] = -scale[i]
+		}
+	}
+	return ComposeMat3x4(&translation, &rotation, &scale)
+}
+
 // Mat4Normal calculates the Normal of the Matrix (aka the inverse transpose)
 func Mat4Normal(m *Mat4) Mat3 {
 	n := m.Inverse()
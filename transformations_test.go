@@ -134,6 +134,219 @@ func TestExtractMaxScale(t *testing.T) {
 	}
 }
 
+func TestDecompose(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		translation Vec3
+		rotation    Quat
+		scale       Vec3
+	}{
+		{Vec3{}, QuatIdent(), Vec3{1, 1, 1}},
+		{Vec3{1, 2, 3}, QuatIdent(), Vec3{1, 1, 1}},
+		{Vec3{}, QuatRotate(1, &Vec3{0, 1, 0}), Vec3{1, 1, 1}},
+		{Vec3{-4, 5, 0.5}, AnglesToQuat(0.3, -1, 2.5, XYZ), Vec3{2, 0.5, 3}},
+		{Vec3{10, -20, 30}, QuatRotate(3, &Vec3{0.6, 0, 0.8}), Vec3{1e-2, 100, 1}},
+		{Vec3{1, 1, 1}, AnglesToQuat(2, 1, -0.5, ZYX), Vec3{-2, 3, 4}},
+	}
+
+	for i, test := range tests {
+		m := Compose(&test.translation, &test.rotation, &test.scale)
+		expected := Translate3D(test.translation[0], test.translation[1], test.translation[2])
+		r, s := test.rotation.Mat4(), Scale3D(test.scale[0], test.scale[1], test.scale[2])
+		expected = expected.Mul4(&r)
+		expected = expected.Mul4(&s)
+		if !near(m[:], expected[:], 1e-4) {
+			t.Errorf("[%d] Compose =\n%swant\n%s", i, m.String(), expected.String())
+		}
+
+		translation, rotation, scale, ok := Decompose(&m)
+		if !ok {
+			t.Errorf("[%d] Decompose failed", i)
+		}
+		if !near(translation[:], test.translation[:], 1e-4) {
+			t.Errorf("[%d] translation = %v, want %v", i, translation, test.translation)
+		}
+		if !rotation.OrientationEqualThreshold(&test.rotation, 1e-5) {
+			t.Errorf("[%d] rotation = %v, want %v", i, rotation, test.rotation)
+		}
+		if !near(scale[:], test.scale[:], 1e-4) {
+			t.Errorf("[%d] scale = %v, want %v", i, scale, test.scale)
+		}
+
+		// The same with a homogeneous scale.
+		scaled := m.Mul(2)
+		if tr, r, s, ok := Decompose(&scaled); !ok || tr != translation || r != rotation || s != scale {
+			t.Errorf("[%d] Decompose of the scaled matrix = %v, %v, %v, %t, want %v, %v, %v, true", i, tr, r, s, ok, translation, rotation, scale)
+		}
+	}
+}
+
+func TestDecompose_Reflection(t *testing.T) {
+	t.Parallel()
+	q := AnglesToQuat(0.4, -0.2, 1, XYZ)
+	for i, scale := range []Vec3{
+		{1, -1, 1},
+		{2, 3, -4},
+		{-1, -1, -1},
+		{-1, -2, 1},
+	} {
+		m := Compose(&Vec3{1, 2, 3}, &q, &scale)
+		translation, rotation, s, ok := Decompose(&m)
+		if !ok {
+			t.Errorf("[%d] Decompose failed", i)
+		}
+
+		// The reflection is on x, but the matrix is the same.
+		if (s[0] < 0) != (scale[0]*scale[1]*scale[2] < 0) || s[1] < 0 || s[2] < 0 {
+			t.Errorf("[%d] scale = %v, want a negative scale on x only for reflections", i, s)
+		}
+		if c := Compose(&translation, &rotation, &s); !near(c[:], m[:], 1e-4) {
+			t.Errorf("[%d] Compose(Decompose(m)) =\n%swant\n%s", i, c.String(), m.String())
+		}
+	}
+}
+
+func TestDecompose_Fail(t *testing.T) {
+	t.Parallel()
+	shear := Ident4()
+	shear[4] = 0.5
+	perspective := Perspective(1, 1, 0.1, 100)
+	tests := []Mat4{shear, perspective, {}}
+
+	for i, m := range tests {
+		if _, rotation, _, ok := Decompose(&m); ok {
+			t.Errorf("[%d] Decompose of\n%ssucceeded", i, m.String())
+		} else if l := rotation.Len(); !FloatEqualThreshold(l, 1, 1e-4) {
+			t.Errorf("[%d] rotation %v isn't normalized", i, rotation)
+		}
+	}
+
+}
+
+func TestDecompose_ZeroScale(t *testing.T) {
+	t.Parallel()
+	translation := Vec3{1, 2, 3}
+	tests := []struct {
+		rotation Quat
+		scale    Vec3
+	}{
+		{QuatIdent(), Vec3{2, 0, 3}},
+		{QuatRotate(1.2, &Vec3{0, 0, 1}), Vec3{1, 0, 1}},
+		{AnglesToQuat(0.4, -0.2, 1, XYZ), Vec3{0, 2, -1}},
+		{AnglesToQuat(0.4, -0.2, 1, XYZ), Vec3{3, 0.5, 0}},
+		// 2 scales of 0.
+		{QuatRotate(1.2, &Vec3{0, 0, 1}), Vec3{2, 0, 0}},
+		{AnglesToQuat(0.4, -0.2, 1, XYZ), Vec3{0, 0, 3}},
+		{AnglesToQuat(0.4, -0.2, 1, XYZ), Vec3{0, -2, 0}},
+	}
+
+	for i, test := range tests {
+		m := Compose(&translation, &test.rotation, &test.scale)
+		tr, r, s, ok := Decompose(&m)
+		if !ok {
+			t.Errorf("[%d] Decompose of\n%sfailed", i, m.String())
+		}
+		if l := r.Len(); !FloatEqualThreshold(l, 1, 1e-4) {
+			t.Errorf("[%d] rotation %v isn't normalized", i, r)
+		}
+		if c := Compose(&tr, &r, &s); !near(c[:], m[:], 1e-4) {
+			t.Errorf("[%d] Compose(Decompose(m)) =\n%swant\n%s", i, c.String(), m.String())
+		}
+	}
+}
+
+func TestDecomposeMat3x4(t *testing.T) {
+	t.Parallel()
+	q := AnglesToQuat(0.4, -0.2, 1, XYZ)
+	translation, scale := Vec3{1, 2, 3}, Vec3{2, -1, 0.5}
+	m := ComposeMat3x4(&translation, &q, &scale)
+	m4 := Compose(&translation, &q, &scale)
+	if expected := m4.Mat3x4(); m != expected {
+		t.Errorf("ComposeMat3x4 =\n%swant\n%s", m.String(), expected.String())
+	}
+
+	tr, r, s, ok := DecomposeMat3x4(&m)
+	if !ok {
+		t.Errorf("DecomposeMat3x4 failed")
+	}
+	if c := ComposeMat3x4(&tr, &r, &s); !near(c[:], m[:], 1e-4) {
+		t.Errorf("ComposeMat3x4(DecomposeMat3x4(m)) =\n%swant\n%s", c.String(), m.String())
+	}
+}
+
+func TestMat3x4Lerp(t *testing.T) {
+	t.Parallel()
+	axis := Vec3{0, 0, 1}
+	compose := func(translation Vec3, angle float32, scale Vec3) Mat3x4 {
+		q := QuatRotate(angle, &axis)
+		return ComposeMat3x4(&translation, &q, &scale)
+	}
+
+	tests := []struct {
+		a, b     Mat3x4
+		amount   float32
+		expected Mat3x4
+	}{
+		{compose(Vec3{1, 2, 3}, 0.5, Vec3{1, 2, 3}), compose(Vec3{-1, 0, 5}, 1.5, Vec3{4, 2, 1}), 0, compose(Vec3{1, 2, 3}, 0.5, Vec3{1, 2, 3})},
+		{compose(Vec3{1, 2, 3}, 0.5, Vec3{1, 2, 3}), compose(Vec3{-1, 0, 5}, 1.5, Vec3{4, 2, 1}), 1, compose(Vec3{-1, 0, 5}, 1.5, Vec3{4, 2, 1})},
+		// A quarter turn keeps its scale, the matrix elements would shrink.
+		{compose(Vec3{}, 0, Vec3{1, 1, 1}), compose(Vec3{}, math.Pi/2, Vec3{1, 1, 1}), 0.5, compose(Vec3{}, math.Pi/4, Vec3{1, 1, 1})},
+		// The scales grow logarithmically.
+		{compose(Vec3{0, 0, 0}, 0, Vec3{1, 1, 1}), compose(Vec3{4, 0, 0}, 0, Vec3{4, 9, 1}), 0.5, compose(Vec3{2, 0, 0}, 0, Vec3{2, 3, 1})},
+		{compose(Vec3{}, 0, Vec3{1, 1, 1}), compose(Vec3{}, 2, Vec3{8, 8, 8}), 1.0 / 3, compose(Vec3{}, 2.0/3, Vec3{2, 2, 2})},
+		// The shortest path.
+		{compose(Vec3{}, -3, Vec3{1, 1, 1}), compose(Vec3{}, 3, Vec3{1, 1, 1}), 0.5, compose(Vec3{}, math.Pi, Vec3{1, 1, 1})},
+		// A scale of 0 keeps the rotation.
+		{compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 0, 1}), compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1}), 0, compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 0, 1})},
+		{compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 0, 1}), compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1}), 1, compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1})},
+		{compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 0, 1}), compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1}), 0.5, compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 0.5, 1})},
+		{compose(Vec3{}, 0.4, Vec3{0, 2, 0}), compose(Vec3{}, 1.2, Vec3{2, 2, 2}), 0, compose(Vec3{}, 0.4, Vec3{0, 2, 0})},
+	}
+
+	for i, test := range tests {
+		if m := Mat3x4Lerp(&test.a, &test.b, test.amount); !near(m[:], test.expected[:], 1e-4) {
+			t.Errorf("[%d] Mat3x4Lerp =\n%swant\n%s", i, m.String(), test.expected.String())
+		}
+	}
+}
+
+func TestMat3x4Blend(t *testing.T) {
+	t.Parallel()
+	axis := Vec3{0, 1, 0}
+	compose := func(translation Vec3, angle float32, scale Vec3) Mat3x4 {
+		q := QuatRotate(angle, &axis)
+		return ComposeMat3x4(&translation, &q, &scale)
+	}
+	m1 := compose(Vec3{1, 0, 0}, 0, Vec3{1, 1, 1})
+	m2 := compose(Vec3{3, 2, 0}, 1, Vec3{4, 1, 9})
+
+	tests := []struct {
+		ms       []Mat3x4
+		weights  []float32
+		expected Mat3x4
+	}{
+		{nil, nil, Ident3x4()},
+		{[]Mat3x4{m1, m2}, []float32{0, 0}, Ident3x4()},
+		{[]Mat3x4{m1, m2}, []float32{1, 0}, m1},
+		{[]Mat3x4{m1, m2}, []float32{0, 3}, m2},
+		{[]Mat3x4{m1, m2}, []float32{1, 1}, compose(Vec3{2, 1, 0}, 0.5, Vec3{2, 1, 3})},
+		{[]Mat3x4{m2, m2, m2}, []float32{0.2, 0.3, 0.5}, m2},
+		{[]Mat3x4{m1, m2, m1}, []float32{1, 2, 1}, compose(Vec3{2, 1, 0}, 0.5, Vec3{2, 1, 3})},
+		// The weights of the rotations are normalized too.
+		{[]Mat3x4{m1, m2}, []float32{-1, -1}, compose(Vec3{2, 1, 0}, 0.5, Vec3{2, 1, 3})},
+		// A scale of 0 keeps the rotation.
+		{[]Mat3x4{m1, compose(Vec3{3, 2, 0}, 1, Vec3{4, 0, 9})}, []float32{1, 1}, compose(Vec3{2, 1, 0}, 0.5, Vec3{2, 0, 3})},
+		{[]Mat3x4{compose(Vec3{1, 2, 3}, 1.2, Vec3{0, 1, 1}), compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1})}, []float32{1, 0}, compose(Vec3{1, 2, 3}, 1.2, Vec3{0, 1, 1})},
+		{[]Mat3x4{compose(Vec3{1, 2, 3}, 1.2, Vec3{0, 1, 1}), compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1})}, []float32{0, 1}, compose(Vec3{1, 2, 3}, 1.2, Vec3{1, 1, 1})},
+	}
+
+	for i, test := range tests {
+		if m := Mat3x4Blend(test.ms, test.weights); !near(m[:], test.expected[:], 1e-4) {
+			t.Errorf("[%d] Mat3x4Blend =\n%swant\n%s", i, m.String(), test.expected.String())
+		}
+	}
+}
+
 func TestTransformCoordinate(t *testing.T) {
 	t.Parallel()
 